@@ -6,11 +6,15 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/kaviraj-j/duoplay/internal/bots"
 	"github.com/kaviraj-j/duoplay/internal/config"
+	"github.com/kaviraj-j/duoplay/internal/events"
+	_ "github.com/kaviraj-j/duoplay/internal/games/all"
 	"github.com/kaviraj-j/duoplay/internal/handler"
 	"github.com/kaviraj-j/duoplay/internal/middleware"
 	"github.com/kaviraj-j/duoplay/internal/repository"
 	"github.com/kaviraj-j/duoplay/internal/service"
+	"github.com/kaviraj-j/duoplay/internal/ws"
 )
 
 type App struct {
@@ -18,19 +22,58 @@ type App struct {
 	userHandler    *handler.UserHandler
 	roomHandler    *handler.RoomHandler
 	gameHandler    *handler.GameHandler
+	metricsHandler *handler.MetricsHandler
+	reportHandler  *handler.ReportHandler
+	matchHandler   *handler.MatchHandler
 	authMiddleware *middleware.AuthMiddleWare
 	roomMiddleware *middleware.RoomMiddleWare
 }
 
 // creates new app
 func Create(config config.Config) (*App, error) {
+	// bots for games without a bot of their own search within this budget
+	bots.ConfigureMCTS(bots.MCTSConfig{
+		Playouts:  config.BotPlayouts,
+		TimeLimit: config.BotTimeLimit,
+	})
+
+	// users, ratings and match history live in the configured storage
+	stores, err := openStores(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// room events are fanned out to the observers of rooms through the event bus, the metrics for now
+	eventBus := events.NewBus()
+	metricsService := service.NewMetricsService(eventBus)
+	metricsHandler := handler.NewMetricsHandler(metricsService)
+
+	// ratings are updated by the room service as rounds finish
+	ratingService := service.NewRatingService(stores.ratings)
+
+	// finished matches are stored by the room service as rounds finish
+	matchService := service.NewMatchService(stores.matches)
+	matchHandler := handler.NewMatchHandler(matchService)
+
+	// blocked words are kept out of chat and user names
+	blockedWords := config.BlockedWords
+	if len(blockedWords) == 0 {
+		blockedWords = service.DefaultBlockedWords
+	}
+	wordFilter := service.NewWordFilter(blockedWords)
+
+	// player reports are filed from the rooms and handled by the admins
+	reportRepository := repository.NewReportRepository()
+	reportService := service.NewReportService(reportRepository)
+	reportHandler := handler.NewReportHandler(reportService)
+
 	// get user repo, service, and handler
-	userRepository := repository.NewUserRepository()
-	userService, err := service.CreateUserService(userRepository, []byte(config.JwtSecret))
+	userRepository := stores.users
+	userService, err := service.CreateUserService(userRepository, []byte(config.JwtSecret), wordFilter, config.AdminUserIDs)
 	if err != nil {
 		return nil, err
 	}
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, ratingService)
 	authMiddleware := middleware.NewAuthMiddleware(userService)
 
 	gameRepo := repository.NewGameRepository()
@@ -40,8 +83,19 @@ func Create(config config.Config) (*App, error) {
 	// room repo, service, handler and middleware
 	roomRepo := repository.NewRoomRepository()
 	queueRepo := repository.NewQueueRepository()
-	roomService := service.NewRoomService(roomRepo, queueRepo, userRepository)
-	roomHandler := handler.NewRoomHandler(roomService)
+	roomService := service.NewRoomService(roomRepo, queueRepo, userRepository, ratingService, matchService, reportService, eventBus, service.RoomConfig{
+		DisconnectGracePeriod: config.DisconnectGracePeriod,
+		RatingWindow:          config.MatchRatingWindow,
+		RatingWindowGrowth:    config.MatchRatingWindowGrowth,
+		BotOfferAfter:         config.MatchBotOfferAfter,
+		UndoLimit:             config.UndoLimit,
+		ChatFilter:            wordFilter,
+	})
+	roomHandler := handler.NewRoomHandler(roomService, ws.Config{
+		WriteWait:    config.WsWriteWait,
+		PongWait:     config.WsPongWait,
+		PingInterval: config.WsPingInterval,
+	})
 	roomMiddleware := middleware.NewRoomMiddleware(roomService)
 
 	app := &App{
@@ -51,6 +105,9 @@ func Create(config config.Config) (*App, error) {
 		roomHandler:    roomHandler,
 		roomMiddleware: roomMiddleware,
 		gameHandler:    gameHandler,
+		metricsHandler: metricsHandler,
+		reportHandler:  reportHandler,
+		matchHandler:   matchHandler,
 	}
 	return app, nil
 }
@@ -86,15 +143,28 @@ func (app *App) setupRouter(router *gin.Engine) {
 	// user related routes
 	router.POST("/user", app.userHandler.NewUser)
 	router.GET("/user/me", app.authMiddleware.IsAuthenticated(), app.userHandler.LoggedInUserDetails)
+	router.GET("/user/me/matches", app.authMiddleware.IsAuthenticated(), app.matchHandler.LoggedInUserMatches)
+
+	// match history routes
+	router.GET("/match/:matchID", app.authMiddleware.IsAuthenticated(), app.matchHandler.GetMatch)
 
 	// room routes
 	router.GET("/room/join", app.authMiddleware.IsAuthenticated(), app.roomHandler.NewRoom)
 	router.GET("/room/:roomID", app.authMiddleware.IsAuthenticated(), app.roomMiddleware.IsRoomOwner(), app.roomHandler.GetRoom)
 	router.GET("/room/:roomID/join", app.authMiddleware.IsAuthenticated(), app.roomHandler.JoinRoom)
+	router.GET("/room/:roomID/watch", app.authMiddleware.IsAuthenticated(), app.roomHandler.WatchRoom)
 	router.GET("/room/:roomID/leave", app.authMiddleware.IsAuthenticated(), app.roomHandler.LeaveRoom)
 	router.GET("/room/joinQueue", app.authMiddleware.IsAuthenticated(), app.roomHandler.JoinWaitingQueue)
+	router.GET("/room/vs-bot", app.authMiddleware.IsAuthenticated(), app.roomHandler.PlayBot)
 
 	// game routes
 	router.GET("/game/list", app.gameHandler.GetGamesList)
 
+	// room event counters, admins only
+	router.GET("/metrics", app.authMiddleware.IsAuthenticated(), app.authMiddleware.IsAdmin(), app.metricsHandler.GetMetrics)
+
+	// moderation routes, admins only
+	router.GET("/admin/reports", app.authMiddleware.IsAuthenticated(), app.authMiddleware.IsAdmin(), app.reportHandler.ListReports)
+	router.POST("/admin/reports/:reportID/resolve", app.authMiddleware.IsAuthenticated(), app.authMiddleware.IsAdmin(), app.reportHandler.ResolveReport)
+
 }
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/kaviraj-j/duoplay/internal/config"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+)
+
+// storage backends that can be picked with the STORAGE config
+const (
+	storageMemory = "memory"
+	storageSQLite = "sqlite"
+)
+
+// defaultSQLitePath is the database file used when SQLITE_PATH is unset
+const defaultSQLitePath = "duoplay.db"
+
+// stores are the repositories that outlive a restart when a database backs them
+type stores struct {
+	users   repository.UserRepository
+	ratings repository.RatingRepository
+	matches repository.MatchRepository
+}
+
+// openStores creates the repositories of the configured storage backend, in memory by default
+func openStores(config config.Config) (stores, error) {
+	switch config.Storage {
+	case "", storageMemory:
+		// users kept in memory get new IDs on every restart, so configured admin IDs could never match them
+		if len(config.AdminUserIDs) > 0 {
+			return stores{}, fmt.Errorf("ADMIN_USER_IDS needs STORAGE=%s, users kept in memory do not keep their IDs", storageSQLite)
+		}
+		return stores{
+			users:   repository.NewUserRepository(),
+			ratings: repository.NewRatingRepository(),
+			matches: repository.NewMatchRepository(),
+		}, nil
+	case storageSQLite:
+		path := config.SQLitePath
+		if path == "" {
+			path = defaultSQLitePath
+		}
+		db, err := repository.OpenSQLite(path)
+		if err != nil {
+			return stores{}, fmt.Errorf("opening sqlite database %s: %w", path, err)
+		}
+		return stores{
+			users:   repository.NewSQLiteUserRepository(db),
+			ratings: repository.NewSQLiteRatingRepository(db),
+			matches: repository.NewSQLiteMatchRepository(db),
+		}, nil
+	default:
+		return stores{}, fmt.Errorf("unknown storage %q, use %q or %q", config.Storage, storageMemory, storageSQLite)
+	}
+}
@@ -0,0 +1,25 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kaviraj-j/duoplay/internal/config"
+)
+
+func TestOpenStoresRequiresSQLiteForAdmins(t *testing.T) {
+	if _, err := openStores(config.Config{AdminUserIDs: []string{"admin"}}); err == nil {
+		t.Error("admins were accepted with users kept in memory")
+	}
+	if _, err := openStores(config.Config{}); err != nil {
+		t.Errorf("memory storage without admins: %v", err)
+	}
+	sqliteConfig := config.Config{
+		AdminUserIDs: []string{"admin"},
+		Storage:      storageSQLite,
+		SQLitePath:   filepath.Join(t.TempDir(), "duoplay.db"),
+	}
+	if _, err := openStores(sqliteConfig); err != nil {
+		t.Errorf("sqlite storage with admins: %v", err)
+	}
+}
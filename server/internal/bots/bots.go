@@ -0,0 +1,119 @@
+// Package bots keeps the bots that can take a seat in a room, game packages register their bots from init.
+// Games without a bot of their own are played by the MCTS bot when they can list their moves and clone themselves
+package bots
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/kaviraj-j/duoplay/internal/games"
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// DefaultDifficulty is used when a player does not pick how well the bot plays
+const DefaultDifficulty = model.BotDifficultyGreedy
+
+// Factory creates a bot for a game that plays at the given difficulty
+type Factory func(difficulty model.BotDifficulty) (model.Bot, error)
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[model.GameType]Factory)
+	// order keeps the registration order so the default game for a bot is stable
+	order []model.GameType
+)
+
+// Register makes a bot available for a game, it panics on duplicate registrations
+// since those are programming errors caught at startup
+func Register(gameType model.GameType, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if factory == nil {
+		panic(fmt.Sprintf("no bot factory given for game type: %s", gameType))
+	}
+	if _, exists := factories[gameType]; exists {
+		panic(fmt.Sprintf("bot already registered for game type: %s", gameType))
+	}
+	factories[gameType] = factory
+	order = append(order, gameType)
+}
+
+// Supports reports whether a bot can play the game
+func Supports(gameType model.GameType) bool {
+	mu.RLock()
+	_, exists := factories[gameType]
+	mu.RUnlock()
+
+	return exists || searchable(gameType)
+}
+
+// Games returns the games a bot can play, the ones with a bot of their own first in registration order
+func Games() []model.GameType {
+	mu.RLock()
+	gameTypes := append([]model.GameType(nil), order...)
+	mu.RUnlock()
+
+	for _, info := range games.List() {
+		if !hasFactory(info.Type) && searchable(info.Type) {
+			gameTypes = append(gameTypes, info.Type)
+		}
+	}
+	return gameTypes
+}
+
+func hasFactory(gameType model.GameType) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	_, exists := factories[gameType]
+	return exists
+}
+
+// searchable reports whether the MCTS bot can play the game
+func searchable(gameType model.GameType) bool {
+	game, err := games.CreateGameFromName(string(gameType))
+	if err != nil {
+		return false
+	}
+	_, ok := game.(searchableGame)
+	return ok
+}
+
+// New creates a bot for the game, an empty difficulty falls back to DefaultDifficulty
+func New(gameType model.GameType, difficulty model.BotDifficulty) (model.Bot, error) {
+	if difficulty == "" {
+		difficulty = DefaultDifficulty
+	}
+	if !difficulty.IsValid() {
+		return nil, fmt.Errorf("unknown bot difficulty: %s", difficulty)
+	}
+
+	mu.RLock()
+	factory, exists := factories[gameType]
+	mu.RUnlock()
+	if exists {
+		return factory(difficulty)
+	}
+	if searchable(gameType) {
+		return NewMCTSBot(difficulty), nil
+	}
+	return nil, fmt.Errorf("no bot for game type: %s", gameType)
+}
+
+// NewPlayer creates a bot for the game and the player that seats it in a room
+func NewPlayer(gameType model.GameType, difficulty model.BotDifficulty) (model.Player, error) {
+	bot, err := New(gameType, difficulty)
+	if err != nil {
+		return model.Player{}, err
+	}
+	return model.Player{
+		User: model.User{
+			ID:    "bot-" + uuid.New().String(),
+			Name:  fmt.Sprintf("Bot (%s)", bot.Difficulty()),
+			IsBot: true,
+		},
+		Bot: bot,
+	}, nil
+}
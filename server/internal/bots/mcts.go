@@ -0,0 +1,195 @@
+package bots
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+const (
+	// DefaultPlayouts and DefaultTimeLimit bound a search when MCTSConfig leaves them unset
+	DefaultPlayouts  = 5000
+	DefaultTimeLimit = time.Second
+	// explorationWeight is the UCT constant that balances trying new moves against replaying good ones
+	explorationWeight = math.Sqrt2
+	// greedyBudgetDivisor cuts the search budget of greedy bots so they make mistakes a perfect bot would not
+	greedyBudgetDivisor = 10
+)
+
+// MCTSConfig bounds how long the Monte Carlo tree search bot thinks, the search stops at whichever limit it
+// reaches first, zero values fall back to the defaults
+type MCTSConfig struct {
+	// Playouts is how many random games are played out per move
+	Playouts int
+	// TimeLimit is the longest the bot thinks about one move
+	TimeLimit time.Duration
+}
+
+var (
+	mctsConfigMu sync.RWMutex
+	mctsConfig   = MCTSConfig{Playouts: DefaultPlayouts, TimeLimit: DefaultTimeLimit}
+)
+
+// ConfigureMCTS sets the search budget of the MCTS bots created from now on
+func ConfigureMCTS(config MCTSConfig) {
+	if config.Playouts <= 0 {
+		config.Playouts = DefaultPlayouts
+	}
+	if config.TimeLimit <= 0 {
+		config.TimeLimit = DefaultTimeLimit
+	}
+	mctsConfigMu.Lock()
+	mctsConfig = config
+	mctsConfigMu.Unlock()
+}
+
+// searchableGame is what the MCTS bot needs from a game to play it
+type searchableGame interface {
+	model.TurnBasedGame
+	model.MoveLister
+	model.Cloner
+}
+
+// MCTSBot plays any game that can list its legal moves and clone itself, it needs no knowledge of the rules
+type MCTSBot struct {
+	difficulty model.BotDifficulty
+	config     MCTSConfig
+}
+
+// NewMCTSBot creates a bot with the configured search budget, scaled down for the easier difficulties
+func NewMCTSBot(difficulty model.BotDifficulty) *MCTSBot {
+	mctsConfigMu.RLock()
+	config := mctsConfig
+	mctsConfigMu.RUnlock()
+
+	if difficulty == model.BotDifficultyGreedy {
+		config.Playouts = max(1, config.Playouts/greedyBudgetDivisor)
+	}
+	return &MCTSBot{difficulty: difficulty, config: config}
+}
+
+func (b *MCTSBot) Difficulty() model.BotDifficulty {
+	return b.difficulty
+}
+
+// ChooseMove searches a copy of the game, the game itself is left as it is
+func (b *MCTSBot) ChooseMove(game model.Game, playerID string) (any, error) {
+	root, ok := game.(searchableGame)
+	if !ok {
+		return nil, fmt.Errorf("game %s cannot be searched", game.GetType())
+	}
+	moves := root.LegalMoves(playerID)
+	if len(moves) == 0 {
+		return nil, fmt.Errorf("no legal moves for %s", playerID)
+	}
+	if len(moves) == 1 || b.difficulty == model.BotDifficultyRandom {
+		return moves[rand.IntN(len(moves))], nil
+	}
+
+	tree := &mctsNode{untried: moves}
+	deadline := time.Now().Add(b.config.TimeLimit)
+	// the first playout always runs, so a spent time limit still leaves the search a move to pick
+	for i := 0; i == 0 || i < b.config.Playouts && time.Now().Before(deadline); i++ {
+		if err := tree.playout(root.Clone().(searchableGame)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(tree.children) == 0 {
+		return moves[0], nil
+	}
+	// the most visited move is the one the search trusts most
+	best := tree.children[0]
+	for _, child := range tree.children[1:] {
+		if child.visits > best.visits {
+			best = child
+		}
+	}
+	return best.move, nil
+}
+
+// mctsNode is a position in the search tree, reached by playing move
+type mctsNode struct {
+	move any
+	// player made move, wins are counted from their side
+	player   string
+	parent   *mctsNode
+	children []*mctsNode
+	// untried are the legal moves that have no child yet
+	untried []any
+	visits  int
+	wins    float64
+}
+
+// playout runs one search iteration on game, a copy of the position at the root:
+// select down the tree, expand one move, play randomly to the end and record the result
+func (n *mctsNode) playout(game searchableGame) error {
+	node := n
+	for len(node.untried) == 0 && len(node.children) > 0 {
+		node = node.bestChild()
+		if err := game.MakeMove(node.player, node.move); err != nil {
+			return err
+		}
+	}
+
+	if len(node.untried) > 0 && !game.IsGameOver() {
+		i := rand.IntN(len(node.untried))
+		move := node.untried[i]
+		node.untried = append(node.untried[:i], node.untried[i+1:]...)
+
+		player := game.CurrentPlayerID()
+		if err := game.MakeMove(player, move); err != nil {
+			return err
+		}
+		child := &mctsNode{move: move, player: player, parent: node}
+		if !game.IsGameOver() {
+			child.untried = game.LegalMoves(game.CurrentPlayerID())
+		}
+		node.children = append(node.children, child)
+		node = child
+	}
+
+	for !game.IsGameOver() {
+		player := game.CurrentPlayerID()
+		moves := game.LegalMoves(player)
+		if len(moves) == 0 {
+			break
+		}
+		if err := game.MakeMove(player, moves[rand.IntN(len(moves))]); err != nil {
+			return err
+		}
+	}
+
+	winnerID := ""
+	if winner := game.GetWinner(); winner != nil {
+		winnerID = winner.User.ID
+	}
+	for ; node != nil; node = node.parent {
+		node.visits++
+		switch winnerID {
+		case "":
+			node.wins += 0.5
+		case node.player:
+			node.wins++
+		}
+	}
+	return nil
+}
+
+// bestChild picks the child with the highest upper confidence bound
+func (n *mctsNode) bestChild() *mctsNode {
+	var best *mctsNode
+	bestScore := math.Inf(-1)
+	logVisits := math.Log(float64(n.visits))
+	for _, child := range n.children {
+		score := child.wins/float64(child.visits) + explorationWeight*math.Sqrt(logVisits/float64(child.visits))
+		if score > bestScore {
+			best, bestScore = child, score
+		}
+	}
+	return best
+}
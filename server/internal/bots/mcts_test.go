@@ -0,0 +1,107 @@
+package bots
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/games/connectfour"
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// newConnectFour returns a running game of connect four with the columns played in turn, red first
+func newConnectFour(t *testing.T, columns ...int) model.Game {
+	t.Helper()
+	game := connectfour.NewConnectFour()
+	players := []model.Player{
+		{User: model.User{ID: "red", Name: "Red"}},
+		{User: model.User{ID: "yellow", Name: "Yellow"}},
+	}
+	if err := game.(model.TurnBasedGame).SeatPlayers(players); err != nil {
+		t.Fatalf("SeatPlayers: %v", err)
+	}
+	if err := game.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	for i, col := range columns {
+		player := players[i%2].User.ID
+		if err := game.MakeMove(player, connectfour.Move{Col: col}); err != nil {
+			t.Fatalf("move %d (%s in column %d): %v", i, player, col, err)
+		}
+	}
+	return game
+}
+
+func newTestMCTSBot(config MCTSConfig) *MCTSBot {
+	return &MCTSBot{difficulty: model.BotDifficultyPerfect, config: config}
+}
+
+func TestMCTSFindsTheKeyMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []int
+		want    connectfour.Move
+	}{
+		// red has three along the bottom row
+		{name: "takes a forced win", columns: []int{0, 6, 1, 6, 2, 5}, want: connectfour.Move{Col: 3}},
+		// yellow has three along the bottom row and red has no win of its own
+		{name: "blocks an immediate loss", columns: []int{6, 0, 6, 1, 5, 2}, want: connectfour.Move{Col: 3}},
+	}
+
+	bot := newTestMCTSBot(MCTSConfig{Playouts: DefaultPlayouts, TimeLimit: 10 * time.Second})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newConnectFour(t, tt.columns...)
+			move, err := bot.ChooseMove(game, "red")
+			if err != nil {
+				t.Fatalf("ChooseMove: %v", err)
+			}
+			if move != tt.want {
+				t.Errorf("ChooseMove = %+v, want %+v", move, tt.want)
+			}
+		})
+	}
+}
+
+func TestMCTSLeavesTheGameAlone(t *testing.T) {
+	game := newConnectFour(t, 3, 3, 4)
+	state, history := game.GetState(), game.(model.Undoer).History()
+
+	bot := newTestMCTSBot(MCTSConfig{Playouts: 500, TimeLimit: 10 * time.Second})
+	if _, err := bot.ChooseMove(game, "yellow"); err != nil {
+		t.Fatalf("ChooseMove: %v", err)
+	}
+
+	if !reflect.DeepEqual(game.GetState(), state) {
+		t.Errorf("the search changed the game state")
+	}
+	if !reflect.DeepEqual(game.(model.Undoer).History(), history) {
+		t.Errorf("the search changed the move history")
+	}
+	if game.GetStatus() != model.GameStatusInProgress || game.GetWinner() != nil {
+		t.Errorf("the search changed the game result, status %s", game.GetStatus())
+	}
+}
+
+func TestMCTSMovesWithoutABudget(t *testing.T) {
+	tests := []struct {
+		name   string
+		config MCTSConfig
+	}{
+		{name: "time limit already spent", config: MCTSConfig{Playouts: DefaultPlayouts, TimeLimit: time.Nanosecond}},
+		{name: "no playouts", config: MCTSConfig{Playouts: 0, TimeLimit: DefaultTimeLimit}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newConnectFour(t, 3)
+			move, err := newTestMCTSBot(tt.config).ChooseMove(game, "yellow")
+			if err != nil {
+				t.Fatalf("ChooseMove: %v", err)
+			}
+			if err := game.MakeMove("yellow", move); err != nil {
+				t.Errorf("ChooseMove returned an illegal move %+v: %v", move, err)
+			}
+		})
+	}
+}
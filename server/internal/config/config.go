@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -11,6 +12,38 @@ import (
 type Config struct {
 	ServerAddress string `mapstructure:"SERVER_ADDRESS"`
 	JwtSecret     string `mapstructure:"JWT_SECRET"`
+
+	// WebSocket heartbeat and write timeouts, zero values fall back to the ws package defaults
+	WsPingInterval time.Duration `mapstructure:"WS_PING_INTERVAL"`
+	WsPongWait     time.Duration `mapstructure:"WS_PONG_WAIT"`
+	WsWriteWait    time.Duration `mapstructure:"WS_WRITE_WAIT"`
+
+	// DisconnectGracePeriod is how long a player who drops mid-game has to reconnect before forfeiting
+	DisconnectGracePeriod time.Duration `mapstructure:"DISCONNECT_GRACE_PERIOD"`
+
+	// Matchmaking rating window in rating points, and how many points it widens by per second of waiting
+	MatchRatingWindow       int `mapstructure:"MATCH_RATING_WINDOW"`
+	MatchRatingWindowGrowth int `mapstructure:"MATCH_RATING_WINDOW_GROWTH"`
+	// MatchBotOfferAfter is how long a queued player waits before being offered a bot, negative turns offers off
+	MatchBotOfferAfter time.Duration `mapstructure:"MATCH_BOT_OFFER_AFTER"`
+
+	// UndoLimit is how many undos every player may have per round, negative turns undos off
+	UndoLimit int `mapstructure:"UNDO_LIMIT"`
+
+	// Search budget of the MCTS bot per move, zero values fall back to the bots package defaults
+	BotPlayouts  int           `mapstructure:"BOT_PLAYOUTS"`
+	BotTimeLimit time.Duration `mapstructure:"BOT_TIME_LIMIT"`
+
+	// BlockedWords are masked in chat and not allowed in user names, comma separated, empty uses the default list
+	BlockedWords []string `mapstructure:"BLOCKED_WORDS"`
+	// AdminUserIDs are the users who can list and resolve player reports and read the metrics, comma separated.
+	// It needs STORAGE=sqlite, in memory users get new IDs on every restart and the server refuses to start
+	AdminUserIDs []string `mapstructure:"ADMIN_USER_IDS"`
+
+	// Storage picks where users, ratings and match history are kept, "memory" (the default) or "sqlite"
+	Storage string `mapstructure:"STORAGE"`
+	// SQLitePath is the database file of the sqlite storage, it defaults to duoplay.db
+	SQLitePath string `mapstructure:"SQLITE_PATH"`
 }
 
 // Load function loads the configs from env file and return Config
@@ -0,0 +1,136 @@
+// Package events fans room events out to the parts of the server that only observe rooms, such as the metrics.
+// Delivery is best effort, so client messages and match records do not go through it: the room sends the
+// former itself and hands the latter to the room service's round queue, neither may be dropped
+package events
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// DefaultBuffer is how many events a subscriber can fall behind by before events are dropped for it
+const DefaultBuffer = 256
+
+// Handler receives the events a subscriber asked for, it runs on the subscriber's own goroutine
+type Handler func(event model.Event)
+
+// Bus delivers every published event to its subscribers, publishing never blocks:
+// each subscriber has a buffered queue and an event is dropped for a subscriber whose queue is full
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[int]*subscriber
+	nextID      int
+}
+
+type subscriber struct {
+	name    string
+	types   map[model.RoomEventType]bool
+	queue   chan model.Event
+	handler Handler
+	dropped atomic.Uint64
+	quit    chan struct{}
+	once    sync.Once
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[int]*subscriber),
+	}
+}
+
+// Subscribe registers handler for the given event types, or for every event when no type is given.
+// The returned function unsubscribes, events still queued for the subscriber are discarded
+func (b *Bus) Subscribe(name string, buffer int, handler Handler, types ...model.RoomEventType) func() {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	sub := &subscriber{
+		name:    name,
+		queue:   make(chan model.Event, buffer),
+		handler: handler,
+		quit:    make(chan struct{}),
+	}
+	if len(types) > 0 {
+		sub.types = make(map[model.RoomEventType]bool, len(types))
+		for _, eventType := range types {
+			sub.types[eventType] = true
+		}
+	}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = sub
+	b.mu.Unlock()
+
+	go sub.run()
+
+	return func() {
+		b.mu.Lock()
+		delete(b.subscribers, id)
+		b.mu.Unlock()
+		sub.stop()
+	}
+}
+
+// Publish hands the event to every interested subscriber without waiting for them
+func (b *Bus) Publish(event model.Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers {
+		if sub.types != nil && !sub.types[event.Type] {
+			continue
+		}
+		select {
+		case sub.queue <- event:
+		default:
+			// a slow subscriber loses events instead of holding up the room that published them
+			if dropped := sub.dropped.Add(1); dropped == 1 || dropped%100 == 0 {
+				fmt.Printf("Event bus: subscriber %s is behind, %d events dropped\n", sub.name, dropped)
+			}
+		}
+	}
+}
+
+// Close unsubscribes everyone
+func (b *Bus) Close() {
+	b.mu.Lock()
+	subscribers := b.subscribers
+	b.subscribers = make(map[int]*subscriber)
+	b.mu.Unlock()
+
+	for _, sub := range subscribers {
+		sub.stop()
+	}
+}
+
+func (s *subscriber) run() {
+	for {
+		select {
+		case <-s.quit:
+			return
+		case event := <-s.queue:
+			s.handle(event)
+		}
+	}
+}
+
+// handle keeps a panicking handler from taking the subscriber down with it
+func (s *subscriber) handle(event model.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("Event bus: subscriber %s failed on %s event: %v\n", s.name, event.Type, r)
+		}
+	}()
+	s.handler(event)
+}
+
+func (s *subscriber) stop() {
+	s.once.Do(func() {
+		close(s.quit)
+	})
+}
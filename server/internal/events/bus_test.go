@@ -0,0 +1,104 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// collect subscribes to the bus and returns the channel the subscriber's events arrive on
+func collect(bus *Bus, buffer int, types ...model.RoomEventType) <-chan model.Event {
+	received := make(chan model.Event, 1000)
+	bus.Subscribe("collector", buffer, func(event model.Event) {
+		received <- event
+	}, types...)
+	return received
+}
+
+// receive waits for count events on received
+func receive(t *testing.T, received <-chan model.Event, count int) []model.Event {
+	t.Helper()
+	events := make([]model.Event, 0, count)
+	for len(events) < count {
+		select {
+		case event := <-received:
+			events = append(events, event)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d events, want %d", len(events), count)
+		}
+	}
+	return events
+}
+
+func TestBusDeliversInOrder(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+	first, second := collect(bus, DefaultBuffer), collect(bus, DefaultBuffer)
+
+	const published = 100
+	for i := 0; i < published; i++ {
+		bus.Publish(model.Event{Type: model.RoomEventTypeMoveMade, Payload: i})
+	}
+
+	for _, received := range []<-chan model.Event{first, second} {
+		for i, event := range receive(t, received, published) {
+			if event.Payload != i {
+				t.Fatalf("event %d has payload %v, events arrived out of order", i, event.Payload)
+			}
+		}
+	}
+}
+
+func TestBusFiltersEventTypes(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+	received := collect(bus, DefaultBuffer, model.RoomEventTypeGameOver)
+
+	bus.Publish(model.Event{Type: model.RoomEventTypeMoveMade, RoomID: "skipped"})
+	bus.Publish(model.Event{Type: model.RoomEventTypeGameOver, RoomID: "delivered"})
+
+	if event := receive(t, received, 1)[0]; event.RoomID != "delivered" {
+		t.Errorf("received the %s event of room %s, want only game over events", event.Type, event.RoomID)
+	}
+}
+
+func TestBusDropsEventsForSlowSubscriber(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+
+	// the slow subscriber is stuck on its first event until release is closed
+	release := make(chan struct{})
+	slow := make(chan model.Event, 1000)
+	bus.Subscribe("slow", 1, func(event model.Event) {
+		<-release
+		slow <- event
+	})
+	fast := collect(bus, 1000)
+
+	const published = 100
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < published; i++ {
+			bus.Publish(model.Event{Type: model.RoomEventTypeMoveMade, Payload: i})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a slow subscriber")
+	}
+
+	// the fast subscriber misses nothing
+	receive(t, fast, published)
+
+	close(release)
+	// the slow subscriber gets the event it was handling and the one its buffer held, the rest were dropped
+	got := receive(t, slow, 1)
+	time.Sleep(50 * time.Millisecond)
+	got = append(got, receive(t, slow, len(slow))...)
+	if len(got) > 2 {
+		t.Errorf("slow subscriber received %d of %d events, want the rest dropped", len(got), published)
+	}
+}
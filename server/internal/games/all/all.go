@@ -0,0 +1,12 @@
+// Package all registers every game shipped with duoplay, import it for its side effects.
+//
+// A game is added by writing a package under internal/games that declares its own model.GameType
+// and calls games.Register from init, then adding a blank import of it below. That import is the
+// only wiring a game needs, the rest of the server finds it through the registry, and TestEveryGameIsImported
+// fails until it is added
+package all
+
+import (
+	_ "github.com/kaviraj-j/duoplay/internal/games/connectfour"
+	_ "github.com/kaviraj-j/duoplay/internal/games/tictactoe"
+)
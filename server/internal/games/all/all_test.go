@@ -0,0 +1,67 @@
+package all
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const gamesImportPath = "github.com/kaviraj-j/duoplay/internal/games/"
+
+// registersGame reports whether a non-test file of the package in dir calls games.Register
+func registersGame(t *testing.T, dir string) bool {
+	t.Helper()
+	packages, err := parser.ParseDir(token.NewFileSet(), dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", dir, err)
+	}
+	found := false
+	for _, pkg := range packages {
+		ast.Inspect(pkg, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return !found
+			}
+			if selector, ok := call.Fun.(*ast.SelectorExpr); ok && selector.Sel.Name == "Register" {
+				if ident, ok := selector.X.(*ast.Ident); ok && ident.Name == "games" {
+					found = true
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// TestEveryGameIsImported fails when a game package under internal/games is missing from the imports of all.go
+func TestEveryGameIsImported(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "all.go", nil, parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("parsing all.go: %v", err)
+	}
+	imported := make(map[string]bool)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		imported[strings.TrimPrefix(path, gamesImportPath)] = true
+	}
+
+	entries, err := os.ReadDir("..")
+	if err != nil {
+		t.Fatalf("reading the games directory: %v", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == "all" {
+			continue
+		}
+		if registersGame(t, filepath.Join("..", entry.Name())) && !imported[entry.Name()] {
+			t.Errorf("game package %s registers a game but all.go does not import it", entry.Name())
+		}
+	}
+}
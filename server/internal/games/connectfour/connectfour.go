@@ -0,0 +1,274 @@
+package connectfour
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/games"
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// GameType identifies the game in the registry and in client messages
+const GameType model.GameType = "connectfour"
+
+func init() {
+	games.Register(games.GameInfo{
+		Type:        GameType,
+		DisplayName: "Connect Four",
+		Description: "Drop discs into a 7x6 grid, first to connect four in a row, column or diagonal wins.",
+		MinPlayers:  2,
+		MaxPlayers:  2,
+	}, games.GameFactoryFunc(func(state *model.GameState) (model.Game, error) {
+		return NewConnectFour(), nil
+	}))
+}
+
+const (
+	Rows    = 6
+	Columns = 7
+	// number of discs in a line needed to win
+	connectLength = 4
+)
+
+type ConnectFourState struct {
+	// Board holds the seat mark (red or yellow) of the disc in each cell, row 0 is the top
+	Board         [Rows][Columns]string `json:"Board"`
+	CurrentPlayer string                `json:"CurrentPlayer"`
+	// History lists the moves of the round in the order they were made
+	History []model.MoveRecord `json:"History"`
+}
+
+// seatMarks are handed out in seat order, the first seat moves first
+var seatMarks = [2]string{"red", "yellow"}
+
+type ConnectFour struct {
+	state     *model.GameState
+	gameState ConnectFourState
+	seats     games.Seats
+}
+
+// Move drops a disc into the given column, the disc lands on the lowest empty row
+type Move struct {
+	Col int `json:"col"`
+}
+
+func NewConnectFour() model.Game {
+	game := &ConnectFour{
+		state: &model.GameState{
+			ID:        games.NewGameID(GameType),
+			Type:      GameType,
+			Name:      "Connect Four",
+			Players:   make(map[string]model.Player),
+			Status:    model.GameStatusNotStarted,
+			CreatedAt: time.Now(),
+		},
+		gameState: ConnectFourState{},
+	}
+	return game
+}
+
+func (c *ConnectFour) GetType() model.GameType {
+	return GameType
+}
+
+// GetState returns a copy of the state, the history is not shared with the game
+func (c *ConnectFour) GetState() any {
+	state := c.gameState
+	state.History = append([]model.MoveRecord{}, c.gameState.History...)
+	return state
+}
+
+func (c *ConnectFour) Start() error {
+	if c.seats.Count() != 2 {
+		return fmt.Errorf("need exactly 2 players to start")
+	}
+	c.state.Status = model.GameStatusInProgress
+	// The first seat moves first
+	c.gameState.CurrentPlayer = c.seats.First()
+	return nil
+}
+
+func (c *ConnectFour) MakeMove(playerID string, move any) error {
+	if c.state.Status != model.GameStatusInProgress {
+		return fmt.Errorf("game not started")
+	}
+	if playerID != c.gameState.CurrentPlayer {
+		return fmt.Errorf("not your turn")
+	}
+	// moves from the server's own bots are already typed, client moves arrive as decoded JSON
+	moveData, ok := move.(Move)
+	if !ok {
+		moveBytes, err := json.Marshal(move)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(moveBytes, &moveData); err != nil {
+			return err
+		}
+	}
+
+	if moveData.Col < 0 || moveData.Col >= Columns {
+		return fmt.Errorf("invalid move")
+	}
+
+	// find the lowest empty row in the column
+	row := -1
+	for r := Rows - 1; r >= 0; r-- {
+		if c.gameState.Board[r][moveData.Col] == "" {
+			row = r
+			break
+		}
+	}
+	if row == -1 {
+		return fmt.Errorf("column is full")
+	}
+
+	c.gameState.Board[row][moveData.Col] = c.seats.MarkOf(playerID)
+	c.gameState.History = append(c.gameState.History, model.MoveRecord{PlayerID: playerID, Move: moveData})
+
+	// Switch player
+	c.gameState.CurrentPlayer = c.seats.Next(playerID)
+
+	if c.IsGameOver() {
+		c.state.Status = model.GameStatusOver
+	}
+
+	return nil
+}
+
+func (c *ConnectFour) IsGameOver() bool {
+	if c.state.Status == model.GameStatusOver {
+		return true
+	}
+
+	// directions to scan from every occupied cell: right, down, down-right, down-left
+	directions := [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+
+	for r := 0; r < Rows; r++ {
+		for col := 0; col < Columns; col++ {
+			owner := c.gameState.Board[r][col]
+			if owner == "" {
+				continue
+			}
+			for _, d := range directions {
+				if c.lineLength(r, col, d[0], d[1], owner) >= connectLength {
+					// Found a winner
+					if winner, exists := c.state.Players[c.seats.PlayerWithMark(owner)]; exists {
+						c.state.Winner = &winner
+					}
+					c.state.Status = model.GameStatusOver
+					return true
+				}
+			}
+		}
+	}
+
+	// Check for draw, the board is full when the top row has no empty cell
+	for col := 0; col < Columns; col++ {
+		if c.gameState.Board[0][col] == "" {
+			return false
+		}
+	}
+	// It's a draw - no winner
+	c.state.Winner = nil
+	c.state.Status = model.GameStatusOver
+	return true
+}
+
+// lineLength counts the consecutive discs of owner starting at (row, col) in the direction (dRow, dCol)
+func (c *ConnectFour) lineLength(row, col, dRow, dCol int, owner string) int {
+	count := 0
+	for row >= 0 && row < Rows && col >= 0 && col < Columns && c.gameState.Board[row][col] == owner {
+		count++
+		row += dRow
+		col += dCol
+	}
+	return count
+}
+
+func (c *ConnectFour) ResetState() error {
+	c.gameState = ConnectFourState{}
+	c.state.Winner = nil
+	c.state.Status = model.GameStatusNotStarted
+	return nil
+}
+
+func (c *ConnectFour) GetWinner() *model.Player {
+	return c.state.Winner
+}
+
+func (c *ConnectFour) GetStatus() model.GameStatus {
+	return c.state.Status
+}
+
+// SeatPlayers seats exactly two players in turn order, the first seat plays red
+func (c *ConnectFour) SeatPlayers(players []model.Player) error {
+	gamePlayers, err := c.seats.Seat(players, seatMarks[:])
+	if err != nil {
+		return err
+	}
+	c.state.Players = gamePlayers
+	return nil
+}
+
+// CurrentPlayerID returns the player to move
+func (c *ConnectFour) CurrentPlayerID() string {
+	return c.gameState.CurrentPlayer
+}
+
+func (c *ConnectFour) GetSeats() []model.Seat {
+	return c.seats.All()
+}
+
+// LegalMoves returns every column that is not full while it is playerID's turn
+func (c *ConnectFour) LegalMoves(playerID string) []any {
+	if c.state.Status != model.GameStatusInProgress || playerID != c.gameState.CurrentPlayer {
+		return nil
+	}
+	moves := make([]any, 0, Columns)
+	for col := 0; col < Columns; col++ {
+		// the top row fills last
+		if c.gameState.Board[0][col] == "" {
+			moves = append(moves, Move{Col: col})
+		}
+	}
+	return moves
+}
+
+// Clone returns a copy of the game that can be played on without touching c
+func (c *ConnectFour) Clone() model.Game {
+	return &ConnectFour{
+		state:     c.state.Clone(),
+		gameState: c.GetState().(ConnectFourState),
+		seats:     c.seats.Clone(),
+	}
+}
+
+// History returns a copy of the round's moves
+func (c *ConnectFour) History() []model.MoveRecord {
+	return append([]model.MoveRecord{}, c.gameState.History...)
+}
+
+// UndoMove lifts the top disc out of the column of the last move and gives the turn back to the player who dropped it
+func (c *ConnectFour) UndoMove() (model.MoveRecord, error) {
+	history := c.gameState.History
+	if len(history) == 0 {
+		return model.MoveRecord{}, fmt.Errorf("no move to undo")
+	}
+	last := history[len(history)-1]
+	move := last.Move.(Move)
+
+	for row := 0; row < Rows; row++ {
+		if c.gameState.Board[row][move.Col] != "" {
+			c.gameState.Board[row][move.Col] = ""
+			break
+		}
+	}
+	c.gameState.History = history[:len(history)-1]
+	c.gameState.CurrentPlayer = last.PlayerID
+	// a move that ended the game is taken back with its result
+	c.state.Winner = nil
+	c.state.Status = model.GameStatusInProgress
+	return last, nil
+}
@@ -0,0 +1,206 @@
+package connectfour
+
+import (
+	"testing"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// newStartedGame returns a running game where red moves first
+func newStartedGame(t *testing.T) *ConnectFour {
+	t.Helper()
+	game := NewConnectFour().(*ConnectFour)
+	players := []model.Player{
+		{User: model.User{ID: "red", Name: "Red"}},
+		{User: model.User{ID: "yellow", Name: "Yellow"}},
+	}
+	if err := game.SeatPlayers(players); err != nil {
+		t.Fatalf("SeatPlayers: %v", err)
+	}
+	if err := game.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	return game
+}
+
+// play drops discs into the columns in turn, starting with red
+func play(t *testing.T, game *ConnectFour, columns []int) {
+	t.Helper()
+	for i, col := range columns {
+		player := "red"
+		if i%2 == 1 {
+			player = "yellow"
+		}
+		if err := game.MakeMove(player, map[string]interface{}{"col": col}); err != nil {
+			t.Fatalf("move %d (%s in column %d): %v", i, player, col, err)
+		}
+	}
+}
+
+func TestWinDetection(t *testing.T) {
+	tests := []struct {
+		name       string
+		columns    []int
+		wantOver   bool
+		wantWinner string
+	}{
+		{name: "horizontal", columns: []int{0, 0, 1, 1, 2, 2, 3}, wantOver: true, wantWinner: "red"},
+		{name: "horizontal away from the edge", columns: []int{1, 0, 2, 1, 3, 2, 4}, wantOver: true, wantWinner: "red"},
+		{name: "vertical", columns: []int{0, 1, 0, 1, 0, 1, 0}, wantOver: true, wantWinner: "red"},
+		{name: "vertical for the second player", columns: []int{6, 0, 1, 0, 1, 0, 1, 0}, wantOver: true, wantWinner: "yellow"},
+		{name: "diagonal rising to the right", columns: []int{0, 1, 1, 2, 2, 3, 2, 3, 3, 5, 3}, wantOver: true, wantWinner: "red"},
+		{name: "diagonal rising to the left", columns: []int{6, 5, 5, 4, 4, 3, 4, 3, 3, 1, 3}, wantOver: true, wantWinner: "red"},
+		{name: "three in a row is not a win", columns: []int{0, 0, 1, 1, 2, 2}, wantOver: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newStartedGame(t)
+			play(t, game, tt.columns)
+
+			if got := game.IsGameOver(); got != tt.wantOver {
+				t.Fatalf("IsGameOver() = %v, want %v", got, tt.wantOver)
+			}
+			winner := game.GetWinner()
+			switch {
+			case tt.wantWinner == "" && winner != nil:
+				t.Errorf("winner = %s, want none", winner.User.ID)
+			case tt.wantWinner != "" && winner == nil:
+				t.Errorf("no winner, want %s", tt.wantWinner)
+			case tt.wantWinner != "" && winner.User.ID != tt.wantWinner:
+				t.Errorf("winner = %s, want %s", winner.User.ID, tt.wantWinner)
+			}
+		})
+	}
+}
+
+func TestMoveValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []int
+		player  string
+		col     int
+	}{
+		{name: "full column", columns: []int{0, 0, 0, 0, 0, 0}, player: "red", col: 0},
+		{name: "column left of the board", player: "red", col: -1},
+		{name: "column right of the board", player: "red", col: Columns},
+		{name: "out of turn", player: "yellow", col: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newStartedGame(t)
+			play(t, game, tt.columns)
+			before := game.GetState().(ConnectFourState)
+
+			if err := game.MakeMove(tt.player, Move{Col: tt.col}); err == nil {
+				t.Fatalf("MakeMove(%s, column %d) succeeded, want an error", tt.player, tt.col)
+			}
+			if after := game.GetState().(ConnectFourState); after.Board != before.Board || after.CurrentPlayer != before.CurrentPlayer {
+				t.Errorf("a rejected move changed the game")
+			}
+		})
+	}
+}
+
+func TestDrawOnFullBoard(t *testing.T) {
+	game := newStartedGame(t)
+	// pairs of columns alternate colour on every row, so no line of four forms anywhere
+	rows := [Rows]string{
+		"rryyrry",
+		"yyrryyr",
+		"rryyrry",
+		"yyrryyr",
+		"rryyrry",
+		"yyrryyr",
+	}
+	marks := map[byte]string{'r': "red", 'y': "yellow"}
+	for r, row := range rows {
+		for c := 0; c < Columns; c++ {
+			game.gameState.Board[r][c] = marks[row[c]]
+		}
+	}
+	// the top left cell is left for red's last move
+	game.gameState.Board[0][0] = ""
+	game.gameState.CurrentPlayer = "red"
+
+	if game.IsGameOver() {
+		t.Fatalf("game is over before the board is full")
+	}
+	if err := game.MakeMove("red", Move{Col: 0}); err != nil {
+		t.Fatalf("MakeMove: %v", err)
+	}
+	if !game.IsGameOver() {
+		t.Fatalf("game is not over on a full board")
+	}
+	if winner := game.GetWinner(); winner != nil {
+		t.Errorf("winner = %s, want a draw", winner.User.ID)
+	}
+	if game.GetStatus() != model.GameStatusOver {
+		t.Errorf("status = %s, want %s", game.GetStatus(), model.GameStatusOver)
+	}
+}
+
+func TestUndoMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []int
+	}{
+		{name: "move in a running game", columns: []int{3, 4}},
+		{name: "disc on top of a stack", columns: []int{3, 3, 3}},
+		{name: "winning move", columns: []int{0, 1, 0, 1, 0, 1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := newStartedGame(t)
+			play(t, before, tt.columns[:len(tt.columns)-1])
+			game := newStartedGame(t)
+			play(t, game, tt.columns)
+			last := Move{Col: tt.columns[len(tt.columns)-1]}
+
+			record, err := game.UndoMove()
+			if err != nil {
+				t.Fatalf("UndoMove: %v", err)
+			}
+			if record.Move != last || record.PlayerID != before.CurrentPlayerID() {
+				t.Errorf("undid %+v by %s, want %+v by %s", record.Move, record.PlayerID, last, before.CurrentPlayerID())
+			}
+			if game.GetStatus() != model.GameStatusInProgress || game.GetWinner() != nil {
+				t.Errorf("after the undo status = %s, winner = %v", game.GetStatus(), game.GetWinner())
+			}
+			after, want := game.GetState().(ConnectFourState), before.GetState().(ConnectFourState)
+			if after.Board != want.Board || after.CurrentPlayer != want.CurrentPlayer || len(after.History) != len(want.History) {
+				t.Errorf("game after the undo differs from the game before the move")
+			}
+		})
+	}
+}
+
+func TestLegalMoves(t *testing.T) {
+	game := newStartedGame(t)
+	// column 2 is filled to the top, red is to move
+	play(t, game, []int{2, 2, 2, 2, 2, 2})
+
+	if moves := game.LegalMoves("yellow"); moves != nil {
+		t.Errorf("LegalMoves for the player waiting = %v, want nil", moves)
+	}
+	moves := game.LegalMoves("red")
+	if len(moves) != Columns-1 {
+		t.Fatalf("LegalMoves = %v, want every column but the full one", moves)
+	}
+	for _, move := range moves {
+		if move.(Move).Col == 2 {
+			t.Errorf("LegalMoves lists the full column 2")
+		}
+	}
+
+	// nobody has legal moves once the game is over
+	game = newStartedGame(t)
+	play(t, game, []int{0, 1, 0, 1, 0, 1, 0})
+	for _, player := range []string{"red", "yellow"} {
+		if moves := game.LegalMoves(player); moves != nil {
+			t.Errorf("LegalMoves for %s after the game = %v, want nil", player, moves)
+		}
+	}
+}
@@ -1,16 +1,16 @@
+// Package games keeps the registry of the games the server can host.
+//
+// A new game lives in its own package under internal/games: it declares its model.GameType and calls
+// Register from init. The one line to add outside that package is its blank import in games/all,
+// which the server imports to register every game; the test in games/all fails while it is missing.
+// Nothing else names a game, rooms, bots and the API find it through the registry
 package games
 
 import (
-	"errors"
-
-	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
 	"github.com/kaviraj-j/duoplay/internal/model"
 )
 
+// CreateGameFromName creates a new game instance from the process-wide registry
 func CreateGameFromName(gameName string) (model.Game, error) {
-	switch gameName {
-	case "tictactoe":
-		return tictactoe.NewTicTacToe(), nil
-	}
-	return nil, errors.New("game not found for the given name")
+	return defaultRegistry.CreateGame(model.GameType(gameName), nil)
 }
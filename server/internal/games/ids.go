@@ -0,0 +1,29 @@
+package games
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// gameIDSuffixes is how many random suffixes a game ID can end in
+const gameIDSuffixes = 100000
+
+// NewGameID returns an ID for a new game of the given type, made of the type, the creation time and a random suffix
+func NewGameID(gameType model.GameType) string {
+	return fmt.Sprintf("%s-%d-%s", gameType, time.Now().Unix(), randomSuffix(gameIDSuffixes))
+}
+
+// randomSuffix returns a random number below max, zero padded to six digits
+func randomSuffix(max int) string {
+	var b [8]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return ""
+	}
+	// the modulo is taken unsigned, converting to int first could make it negative
+	return fmt.Sprintf("%06d", binary.BigEndian.Uint64(b[:])%uint64(max))
+}
@@ -2,6 +2,7 @@ package games
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/kaviraj-j/duoplay/internal/model"
 )
@@ -10,24 +11,105 @@ type GameFactory interface {
 	CreateGame(state *model.GameState) (model.Game, error)
 }
 
+// GameFactoryFunc lets a plain function be used as a GameFactory
+type GameFactoryFunc func(state *model.GameState) (model.Game, error)
+
+func (f GameFactoryFunc) CreateGame(state *model.GameState) (model.Game, error) {
+	return f(state)
+}
+
+// GameInfo describes a registered game to the rest of the app
+type GameInfo struct {
+	Type        model.GameType
+	DisplayName string
+	Description string
+	MinPlayers  int
+	MaxPlayers  int
+}
+
+type registeredGame struct {
+	info    GameInfo
+	factory GameFactory
+}
+
 type gameRegistry struct {
-	factories map[model.GameType]GameFactory
+	games map[model.GameType]registeredGame
+	// order keeps the registration order so listings are stable
+	order []model.GameType
+	mu    sync.RWMutex
 }
 
+// defaultRegistry is the process-wide registry, game packages add themselves to it from init
+var defaultRegistry = NewGameRegistry()
+
 func NewGameRegistry() *gameRegistry {
 	return &gameRegistry{
-		factories: make(map[model.GameType]GameFactory),
+		games: make(map[model.GameType]registeredGame),
 	}
 }
 
-func (r *gameRegistry) Register(gameType model.GameType, factory GameFactory) {
-	r.factories[gameType] = factory
+func (r *gameRegistry) Register(info GameInfo, factory GameFactory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if info.Type == "" {
+		return fmt.Errorf("game type is required")
+	}
+	if factory == nil {
+		return fmt.Errorf("no factory given for game type: %s", info.Type)
+	}
+	if _, exists := r.games[info.Type]; exists {
+		return fmt.Errorf("game type already registered: %s", info.Type)
+	}
+	r.games[info.Type] = registeredGame{info: info, factory: factory}
+	r.order = append(r.order, info.Type)
+	return nil
 }
 
 func (r *gameRegistry) CreateGame(gameType model.GameType, state *model.GameState) (model.Game, error) {
-	factory, exists := r.factories[gameType]
+	r.mu.RLock()
+	game, exists := r.games[gameType]
+	r.mu.RUnlock()
+
 	if !exists {
 		return nil, fmt.Errorf("no factory registered for game type: %s", gameType)
 	}
-	return factory.CreateGame(state)
+	return game.factory.CreateGame(state)
+}
+
+func (r *gameRegistry) Lookup(gameType model.GameType) (GameInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	game, exists := r.games[gameType]
+	return game.info, exists
+}
+
+func (r *gameRegistry) List() []GameInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]GameInfo, 0, len(r.order))
+	for _, gameType := range r.order {
+		infos = append(infos, r.games[gameType].info)
+	}
+	return infos
+}
+
+// Register adds a game to the process-wide registry, it panics on invalid or duplicate
+// registrations since those are programming errors caught at startup
+func Register(info GameInfo, factory GameFactory) {
+	if err := defaultRegistry.Register(info, factory); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the metadata of a registered game
+func Lookup(gameType model.GameType) (GameInfo, bool) {
+	return defaultRegistry.Lookup(gameType)
+}
+
+// List returns every registered game in registration order
+func List() []GameInfo {
+	return defaultRegistry.List()
 }
@@ -0,0 +1,91 @@
+package games
+
+import (
+	"fmt"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// Seats is the seating of a turn-based game, games keep one to seat their players and look seats up
+type Seats struct {
+	seats []model.Seat
+}
+
+// Seat seats the players in turn order, the player at index i gets marks[i]. It needs exactly one player
+// per mark and returns the players as the game keeps them, without their connections
+func (s *Seats) Seat(players []model.Player, marks []string) (map[string]model.Player, error) {
+	if len(players) != len(marks) {
+		return nil, fmt.Errorf("need exactly %d players to seat", len(marks))
+	}
+	gamePlayers := make(map[string]model.Player)
+	seats := make([]model.Seat, 0, len(players))
+	for i, p := range players {
+		if _, exists := gamePlayers[p.User.ID]; exists {
+			return nil, fmt.Errorf("player %s is already seated", p.User.ID)
+		}
+		// Game doesn't need connections
+		gamePlayers[p.User.ID] = model.Player{User: p.User}
+		seats = append(seats, model.Seat{
+			Index:    i,
+			Mark:     marks[i],
+			PlayerID: p.User.ID,
+		})
+	}
+	s.seats = seats
+	return gamePlayers, nil
+}
+
+// Count returns how many players are seated
+func (s *Seats) Count() int {
+	return len(s.seats)
+}
+
+// First returns the player in the first seat, they move first
+func (s *Seats) First() string {
+	if len(s.seats) == 0 {
+		return ""
+	}
+	return s.seats[0].PlayerID
+}
+
+// All returns a copy of the seats in turn order
+func (s *Seats) All() []model.Seat {
+	seats := make([]model.Seat, len(s.seats))
+	copy(seats, s.seats)
+	return seats
+}
+
+// Clone returns seating that shares nothing with s
+func (s *Seats) Clone() Seats {
+	return Seats{seats: s.All()}
+}
+
+// Next returns the player seated after playerID, wrapping around to the first seat
+func (s *Seats) Next(playerID string) string {
+	for i, seat := range s.seats {
+		if seat.PlayerID == playerID {
+			return s.seats[(i+1)%len(s.seats)].PlayerID
+		}
+	}
+	return ""
+}
+
+// MarkOf returns the seat mark of playerID
+func (s *Seats) MarkOf(playerID string) string {
+	for _, seat := range s.seats {
+		if seat.PlayerID == playerID {
+			return seat.Mark
+		}
+	}
+	return ""
+}
+
+// PlayerWithMark returns the ID of the player seated with mark
+func (s *Seats) PlayerWithMark(mark string) string {
+	for _, seat := range s.seats {
+		if seat.Mark == mark {
+			return seat.PlayerID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package games
+
+import (
+	"testing"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+func seatTestPlayers(ids ...string) []model.Player {
+	players := make([]model.Player, 0, len(ids))
+	for _, id := range ids {
+		players = append(players, model.Player{User: model.User{ID: id, Name: id}})
+	}
+	return players
+}
+
+func TestSeatsSeat(t *testing.T) {
+	var seats Seats
+	gamePlayers, err := seats.Seat(seatTestPlayers("b", "a"), []string{"X", "O"})
+	if err != nil {
+		t.Fatalf("Seat: %v", err)
+	}
+	if len(gamePlayers) != 2 || gamePlayers["a"].User.ID != "a" || gamePlayers["b"].User.ID != "b" {
+		t.Fatalf("seated players = %v", gamePlayers)
+	}
+
+	// seat order follows the order the players were given in, not their IDs
+	if got := seats.First(); got != "b" {
+		t.Errorf("First() = %q, want b", got)
+	}
+	if got := seats.Next("b"); got != "a" {
+		t.Errorf("Next(b) = %q, want a", got)
+	}
+	if got := seats.Next("a"); got != "b" {
+		t.Errorf("Next(a) = %q, want b, turns wrap around", got)
+	}
+	if got := seats.MarkOf("a"); got != "O" {
+		t.Errorf("MarkOf(a) = %q, want O", got)
+	}
+	if got := seats.PlayerWithMark("X"); got != "b" {
+		t.Errorf("PlayerWithMark(X) = %q, want b", got)
+	}
+	if got := seats.Next("stranger"); got != "" {
+		t.Errorf("Next(stranger) = %q, want empty", got)
+	}
+}
+
+func TestSeatsRejectsBadSeating(t *testing.T) {
+	tests := []struct {
+		name    string
+		players []model.Player
+	}{
+		{name: "too few players", players: seatTestPlayers("a")},
+		{name: "too many players", players: seatTestPlayers("a", "b", "c")},
+		{name: "same player twice", players: seatTestPlayers("a", "a")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seats Seats
+			if _, err := seats.Seat(seatTestPlayers("x", "y"), []string{"X", "O"}); err != nil {
+				t.Fatalf("Seat: %v", err)
+			}
+			if _, err := seats.Seat(tt.players, []string{"X", "O"}); err == nil {
+				t.Fatalf("Seat succeeded, want an error")
+			}
+			// a rejected seating leaves the previous one in place
+			if seats.Count() != 2 || seats.First() != "x" {
+				t.Errorf("seats changed to %v", seats.All())
+			}
+		})
+	}
+}
+
+func TestSeatsCloneAndAllAreCopies(t *testing.T) {
+	var seats Seats
+	if _, err := seats.Seat(seatTestPlayers("a", "b"), []string{"X", "O"}); err != nil {
+		t.Fatalf("Seat: %v", err)
+	}
+	clone := seats.Clone()
+	all := seats.All()
+	all[0].PlayerID = "changed"
+	if _, err := clone.Seat(seatTestPlayers("c", "d"), []string{"X", "O"}); err != nil {
+		t.Fatalf("Seat: %v", err)
+	}
+	if seats.First() != "a" || seats.Next("a") != "b" {
+		t.Errorf("original seats changed to %v", seats.All())
+	}
+}
@@ -0,0 +1,161 @@
+package tictactoe
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/kaviraj-j/duoplay/internal/bots"
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+func init() {
+	bots.Register(GameType, func(difficulty model.BotDifficulty) (model.Bot, error) {
+		return &Bot{difficulty: difficulty}, nil
+	})
+}
+
+// Bot plays tic tac toe, at perfect difficulty it runs a full minimax search which the 3x3 board keeps cheap
+type Bot struct {
+	difficulty model.BotDifficulty
+}
+
+func (b *Bot) Difficulty() model.BotDifficulty {
+	return b.difficulty
+}
+
+func (b *Bot) ChooseMove(game model.Game, playerID string) (any, error) {
+	state, ok := game.GetState().(TicTacToeState)
+	if !ok {
+		return nil, fmt.Errorf("tic tac toe bot cannot play %s", game.GetType())
+	}
+	if state.CurrentPlayer != playerID {
+		return nil, fmt.Errorf("not the bot's turn")
+	}
+
+	var mark, opponentMark string
+	for _, seat := range game.GetSeats() {
+		if seat.PlayerID == playerID {
+			mark = seat.Mark
+		} else {
+			opponentMark = seat.Mark
+		}
+	}
+	if mark == "" {
+		return nil, fmt.Errorf("bot is not seated")
+	}
+
+	moves := openCells(state.Board)
+	if len(moves) == 0 {
+		return nil, fmt.Errorf("no moves left")
+	}
+
+	switch b.difficulty {
+	case model.BotDifficultyRandom:
+		return moves[rand.IntN(len(moves))], nil
+	case model.BotDifficultyGreedy:
+		return greedyMove(state.Board, mark, opponentMark, moves), nil
+	default:
+		return perfectMove(state.Board, mark, opponentMark, moves), nil
+	}
+}
+
+// greedyMove wins when it can, blocks the opponent's winning move, and otherwise prefers the centre,
+// then the corners, then whatever is left
+func greedyMove(board [3][3]string, mark, opponentMark string, moves []Move) Move {
+	for _, m := range []string{mark, opponentMark} {
+		for _, move := range moves {
+			board[move.Row][move.Col] = m
+			winner := lineWinner(board)
+			board[move.Row][move.Col] = ""
+			if winner == m {
+				return move
+			}
+		}
+	}
+
+	preferred := []Move{{1, 1}, {0, 0}, {0, 2}, {2, 0}, {2, 2}}
+	rand.Shuffle(len(preferred)-1, func(i, j int) {
+		preferred[i+1], preferred[j+1] = preferred[j+1], preferred[i+1]
+	})
+	for _, move := range preferred {
+		if board[move.Row][move.Col] == "" {
+			return move
+		}
+	}
+	return moves[rand.IntN(len(moves))]
+}
+
+// perfectMove returns one of the moves with the best minimax score, picked at random so games vary
+func perfectMove(board [3][3]string, mark, opponentMark string, moves []Move) Move {
+	var best []Move
+	bestScore := 0
+	for _, move := range moves {
+		board[move.Row][move.Col] = mark
+		score := -minimax(&board, opponentMark, mark, 1)
+		board[move.Row][move.Col] = ""
+
+		if len(best) == 0 || score > bestScore {
+			best = []Move{move}
+			bestScore = score
+		} else if score == bestScore {
+			best = append(best, move)
+		}
+	}
+	return best[rand.IntN(len(best))]
+}
+
+// minimax scores the board for the player to move, a quicker win scores higher and a slower loss less low
+func minimax(board *[3][3]string, toMove, other string, depth int) int {
+	switch lineWinner(*board) {
+	case toMove:
+		return 10 - depth
+	case other:
+		return depth - 10
+	}
+
+	best, moved := 0, false
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			if board[row][col] != "" {
+				continue
+			}
+			board[row][col] = toMove
+			score := -minimax(board, other, toMove, depth+1)
+			board[row][col] = ""
+			if !moved || score > best {
+				best, moved = score, true
+			}
+		}
+	}
+	// a full board without a line is a draw
+	return best
+}
+
+// openCells returns the cells nobody has marked yet
+func openCells(board [3][3]string) []Move {
+	moves := make([]Move, 0, 9)
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			if board[row][col] == "" {
+				moves = append(moves, Move{Row: row, Col: col})
+			}
+		}
+	}
+	return moves
+}
+
+// lineWinner returns the mark that has three in a row, or empty when nobody has
+func lineWinner(board [3][3]string) string {
+	lines := [8][3][2]int{
+		{{0, 0}, {0, 1}, {0, 2}}, {{1, 0}, {1, 1}, {1, 2}}, {{2, 0}, {2, 1}, {2, 2}},
+		{{0, 0}, {1, 0}, {2, 0}}, {{0, 1}, {1, 1}, {2, 1}}, {{0, 2}, {1, 2}, {2, 2}},
+		{{0, 0}, {1, 1}, {2, 2}}, {{0, 2}, {1, 1}, {2, 0}},
+	}
+	for _, line := range lines {
+		a, b, c := line[0], line[1], line[2]
+		if mark := board[a[0]][a[1]]; mark != "" && mark == board[b[0]][b[1]] && mark == board[c[0]][c[1]] {
+			return mark
+		}
+	}
+	return ""
+}
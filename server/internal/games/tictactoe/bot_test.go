@@ -0,0 +1,122 @@
+package tictactoe
+
+import (
+	"testing"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// newStartedGame returns a running game where x moves first, with the moves played in turn from x
+func newStartedGame(t *testing.T, moves ...Move) *TicTacToe {
+	t.Helper()
+	game := NewTicTacToe().(*TicTacToe)
+	players := []model.Player{
+		{User: model.User{ID: "x", Name: "X"}},
+		{User: model.User{ID: "o", Name: "O"}},
+	}
+	if err := game.SeatPlayers(players); err != nil {
+		t.Fatalf("SeatPlayers: %v", err)
+	}
+	if err := game.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	for _, move := range moves {
+		player := game.CurrentPlayerID()
+		if err := game.MakeMove(player, move); err != nil {
+			t.Fatalf("%s playing %+v: %v", player, move, err)
+		}
+	}
+	return game
+}
+
+func chooseMove(t *testing.T, difficulty model.BotDifficulty, game *TicTacToe) Move {
+	t.Helper()
+	bot := &Bot{difficulty: difficulty}
+	move, err := bot.ChooseMove(game, game.CurrentPlayerID())
+	if err != nil {
+		t.Fatalf("ChooseMove: %v", err)
+	}
+	return move.(Move)
+}
+
+// neverLoses plays every line of the opponent against the perfect bot from the position in game
+// and fails the test on any line the bot loses
+func neverLoses(t *testing.T, game *TicTacToe, botID string, line []Move) {
+	t.Helper()
+	if game.IsGameOver() {
+		if winner := game.GetWinner(); winner != nil && winner.User.ID != botID {
+			t.Fatalf("perfect bot lost after %+v", line)
+		}
+		return
+	}
+
+	player := game.CurrentPlayerID()
+	if player == botID {
+		move := chooseMove(t, model.BotDifficultyPerfect, game)
+		next := game.Clone().(*TicTacToe)
+		if err := next.MakeMove(player, move); err != nil {
+			t.Fatalf("perfect bot played %+v after %+v: %v", move, line, err)
+		}
+		neverLoses(t, next, botID, append(line, move))
+		return
+	}
+	for _, move := range game.LegalMoves(player) {
+		next := game.Clone().(*TicTacToe)
+		if err := next.MakeMove(player, move); err != nil {
+			t.Fatalf("opponent playing %+v after %+v: %v", move, line, err)
+		}
+		neverLoses(t, next, botID, append(append([]Move(nil), line...), move.(Move)))
+	}
+}
+
+func TestPerfectBotNeverLoses(t *testing.T) {
+	for _, botID := range []string{"x", "o"} {
+		t.Run("bot plays "+botID, func(t *testing.T) {
+			neverLoses(t, newStartedGame(t), botID, nil)
+		})
+	}
+}
+
+func TestBotsTakeAnImmediateWin(t *testing.T) {
+	// x has the top row open at the right and o threatens the middle row, x to move
+	game := newStartedGame(t, Move{0, 0}, Move{1, 0}, Move{0, 1}, Move{1, 1})
+	for _, difficulty := range []model.BotDifficulty{model.BotDifficultyPerfect, model.BotDifficultyGreedy} {
+		if move := chooseMove(t, difficulty, game); move != (Move{Row: 0, Col: 2}) {
+			t.Errorf("%s bot played %+v, want the winning move {0 2}", difficulty, move)
+		}
+	}
+}
+
+func TestGreedyBotBlocksAThreat(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves []Move
+		want  Move
+	}{
+		{name: "row", moves: []Move{{1, 1}, {0, 0}, {2, 2}, {0, 1}}, want: Move{0, 2}},
+		{name: "column", moves: []Move{{0, 1}, {0, 0}, {2, 2}, {1, 0}}, want: Move{2, 0}},
+		{name: "diagonal", moves: []Move{{0, 1}, {0, 0}, {2, 1}, {1, 1}}, want: Move{2, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newStartedGame(t, tt.moves...)
+			if move := chooseMove(t, model.BotDifficultyGreedy, game); move != tt.want {
+				t.Errorf("greedy bot played %+v, want the block %+v", move, tt.want)
+			}
+		})
+	}
+}
+
+func TestRandomBotPlaysLegalMoves(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		game := newStartedGame(t, Move{1, 1}, Move{0, 0}, Move{2, 2})
+		for !game.IsGameOver() {
+			player := game.CurrentPlayerID()
+			move := chooseMove(t, model.BotDifficultyRandom, game)
+			if err := game.MakeMove(player, move); err != nil {
+				t.Fatalf("random bot played %+v: %v", move, err)
+			}
+		}
+	}
+}
@@ -1,32 +1,44 @@
 package tictactoe
 
 import (
-	"crypto/rand"
-	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/kaviraj-j/duoplay/internal/games"
 	"github.com/kaviraj-j/duoplay/internal/model"
 )
 
-func getRandomId(max int) string {
-	var b [8]byte
-	_, err := rand.Read(b[:])
-	if err != nil {
-		return ""
-	}
-	return fmt.Sprintf("%06d", int(binary.BigEndian.Uint64(b[:]))%max)
+// GameType identifies the game in the registry and in client messages
+const GameType model.GameType = "tictactoe"
+
+func init() {
+	games.Register(games.GameInfo{
+		Type:        GameType,
+		DisplayName: "Tic Tac Toe",
+		Description: "Take turns marking a 3x3 grid, first to line up three marks wins.",
+		MinPlayers:  2,
+		MaxPlayers:  2,
+	}, games.GameFactoryFunc(func(state *model.GameState) (model.Game, error) {
+		return NewTicTacToe(), nil
+	}))
 }
 
 type TicTacToeState struct {
+	// Board holds the seat mark (X or O) of the player who took each cell
 	Board         [3][3]string `json:"Board"`
 	CurrentPlayer string       `json:"CurrentPlayer"`
+	// History lists the moves of the round in the order they were made
+	History []model.MoveRecord `json:"History"`
 }
 
+// seatMarks are handed out in seat order, the first seat moves first
+var seatMarks = [2]string{"X", "O"}
+
 type TicTacToe struct {
 	state     *model.GameState
 	gameState TicTacToeState
+	seats     games.Seats
 }
 
 type Move struct {
@@ -37,8 +49,8 @@ type Move struct {
 func NewTicTacToe() model.Game {
 	game := &TicTacToe{
 		state: &model.GameState{
-			ID:        fmt.Sprintf("tictactoe-%d-%v", time.Now().Unix(), getRandomId(100000)),
-			Type:      model.TicTacToeGame,
+			ID:        games.NewGameID(GameType),
+			Type:      GameType,
 			Name:      "Tic Tac Toe",
 			Players:   make(map[string]model.Player),
 			Status:    model.GameStatusNotStarted,
@@ -56,23 +68,23 @@ func NewTicTacToe() model.Game {
 }
 
 func (t *TicTacToe) GetType() model.GameType {
-	return model.TicTacToeGame
+	return GameType
 }
 
+// GetState returns a copy of the state, the history is not shared with the game
 func (t *TicTacToe) GetState() any {
-	return t.gameState
+	state := t.gameState
+	state.History = append([]model.MoveRecord{}, t.gameState.History...)
+	return state
 }
 
 func (t *TicTacToe) Start() error {
-	if len(t.state.Players) != 2 {
+	if t.seats.Count() != 2 {
 		return fmt.Errorf("need exactly 2 players to start")
 	}
 	t.state.Status = model.GameStatusInProgress
-	// Set first player
-	for playerID := range t.state.Players {
-		t.gameState.CurrentPlayer = playerID
-		break
-	}
+	// The first seat moves first
+	t.gameState.CurrentPlayer = t.seats.First()
 	return nil
 }
 
@@ -83,13 +95,16 @@ func (t *TicTacToe) MakeMove(playerID string, move any) error {
 	if playerID != t.gameState.CurrentPlayer {
 		return fmt.Errorf("not your turn")
 	}
-	var moveData Move
-	moveBytes, err := json.Marshal(move)
-	if err != nil {
-		return err
-	}
-	if err := json.Unmarshal(moveBytes, &moveData); err != nil {
-		return err
+	// moves from the server's own bots are already typed, client moves arrive as decoded JSON
+	moveData, ok := move.(Move)
+	if !ok {
+		moveBytes, err := json.Marshal(move)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(moveBytes, &moveData); err != nil {
+			return err
+		}
 	}
 
 	if moveData.Row < 0 || moveData.Row > 2 || moveData.Col < 0 || moveData.Col > 2 {
@@ -100,15 +115,11 @@ func (t *TicTacToe) MakeMove(playerID string, move any) error {
 		return fmt.Errorf("position already taken")
 	}
 
-	t.gameState.Board[moveData.Row][moveData.Col] = playerID
+	t.gameState.Board[moveData.Row][moveData.Col] = t.seats.MarkOf(playerID)
+	t.gameState.History = append(t.gameState.History, model.MoveRecord{PlayerID: playerID, Move: moveData})
 
 	// Switch player
-	for pid := range t.state.Players {
-		if pid != playerID {
-			t.gameState.CurrentPlayer = pid
-			break
-		}
-	}
+	t.gameState.CurrentPlayer = t.seats.Next(playerID)
 
 	if t.IsGameOver() {
 		t.state.Status = model.GameStatusOver
@@ -128,7 +139,7 @@ func (t *TicTacToe) IsGameOver() bool {
 			t.gameState.Board[i][0] == t.gameState.Board[i][1] &&
 			t.gameState.Board[i][1] == t.gameState.Board[i][2] {
 			// Found a winner
-			winnerID := t.gameState.Board[i][0]
+			winnerID := t.seats.PlayerWithMark(t.gameState.Board[i][0])
 			if winner, exists := t.state.Players[winnerID]; exists {
 				t.state.Winner = &winner
 			}
@@ -143,7 +154,7 @@ func (t *TicTacToe) IsGameOver() bool {
 			t.gameState.Board[0][i] == t.gameState.Board[1][i] &&
 			t.gameState.Board[1][i] == t.gameState.Board[2][i] {
 			// Found a winner
-			winnerID := t.gameState.Board[0][i]
+			winnerID := t.seats.PlayerWithMark(t.gameState.Board[0][i])
 			if winner, exists := t.state.Players[winnerID]; exists {
 				t.state.Winner = &winner
 			}
@@ -157,7 +168,7 @@ func (t *TicTacToe) IsGameOver() bool {
 		t.gameState.Board[0][0] == t.gameState.Board[1][1] &&
 		t.gameState.Board[1][1] == t.gameState.Board[2][2] {
 		// Found a winner
-		winnerID := t.gameState.Board[0][0]
+		winnerID := t.seats.PlayerWithMark(t.gameState.Board[0][0])
 		if winner, exists := t.state.Players[winnerID]; exists {
 			t.state.Winner = &winner
 		}
@@ -168,7 +179,7 @@ func (t *TicTacToe) IsGameOver() bool {
 		t.gameState.Board[0][2] == t.gameState.Board[1][1] &&
 		t.gameState.Board[1][1] == t.gameState.Board[2][0] {
 		// Found a winner
-		winnerID := t.gameState.Board[0][2]
+		winnerID := t.seats.PlayerWithMark(t.gameState.Board[0][2])
 		if winner, exists := t.state.Players[winnerID]; exists {
 			t.state.Winner = &winner
 		}
@@ -198,6 +209,8 @@ func (t *TicTacToe) ResetState() error {
 			{"", "", ""},
 		},
 	}
+	t.state.Winner = nil
+	t.state.Status = model.GameStatusNotStarted
 	return nil
 }
 
@@ -209,7 +222,69 @@ func (t *TicTacToe) GetStatus() model.GameStatus {
 	return t.state.Status
 }
 
-// SetPlayers sets the players for the game
-func (t *TicTacToe) SetPlayers(players map[string]model.Player) {
-	t.state.Players = players
+// SeatPlayers seats exactly two players in turn order, the first seat plays X
+func (t *TicTacToe) SeatPlayers(players []model.Player) error {
+	gamePlayers, err := t.seats.Seat(players, seatMarks[:])
+	if err != nil {
+		return err
+	}
+	t.state.Players = gamePlayers
+	return nil
+}
+
+// CurrentPlayerID returns the player to move
+func (t *TicTacToe) CurrentPlayerID() string {
+	return t.gameState.CurrentPlayer
+}
+
+func (t *TicTacToe) GetSeats() []model.Seat {
+	return t.seats.All()
+}
+
+// LegalMoves returns every open cell while it is playerID's turn
+func (t *TicTacToe) LegalMoves(playerID string) []any {
+	if t.state.Status != model.GameStatusInProgress || playerID != t.gameState.CurrentPlayer {
+		return nil
+	}
+	moves := make([]any, 0, 9)
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			if t.gameState.Board[row][col] == "" {
+				moves = append(moves, Move{Row: row, Col: col})
+			}
+		}
+	}
+	return moves
+}
+
+// Clone returns a copy of the game that can be played on without touching t
+func (t *TicTacToe) Clone() model.Game {
+	return &TicTacToe{
+		state:     t.state.Clone(),
+		gameState: t.GetState().(TicTacToeState),
+		seats:     t.seats.Clone(),
+	}
+}
+
+// History returns a copy of the round's moves
+func (t *TicTacToe) History() []model.MoveRecord {
+	return append([]model.MoveRecord{}, t.gameState.History...)
+}
+
+// UndoMove clears the cell of the last move and gives the turn back to the player who made it
+func (t *TicTacToe) UndoMove() (model.MoveRecord, error) {
+	history := t.gameState.History
+	if len(history) == 0 {
+		return model.MoveRecord{}, fmt.Errorf("no move to undo")
+	}
+	last := history[len(history)-1]
+	move := last.Move.(Move)
+
+	t.gameState.Board[move.Row][move.Col] = ""
+	t.gameState.History = history[:len(history)-1]
+	t.gameState.CurrentPlayer = last.PlayerID
+	// a move that ended the game is taken back with its result
+	t.state.Winner = nil
+	t.state.Status = model.GameStatusInProgress
+	return last, nil
 }
@@ -0,0 +1,81 @@
+package tictactoe
+
+import (
+	"testing"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+func TestUndoMove(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves []Move
+	}{
+		{name: "move in a running game", moves: []Move{{0, 0}, {1, 1}, {0, 1}}},
+		// x completes the top row
+		{name: "winning move", moves: []Move{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0, 2}}},
+		// the ninth move fills the board without a line
+		{name: "move that draws", moves: []Move{{0, 0}, {0, 1}, {0, 2}, {1, 1}, {1, 0}, {1, 2}, {2, 1}, {2, 0}, {2, 2}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := newStartedGame(t, tt.moves[:len(tt.moves)-1]...)
+			game := newStartedGame(t, tt.moves...)
+			last := tt.moves[len(tt.moves)-1]
+
+			record, err := game.UndoMove()
+			if err != nil {
+				t.Fatalf("UndoMove: %v", err)
+			}
+			if record.Move != last || record.PlayerID != before.CurrentPlayerID() {
+				t.Errorf("undid %+v by %s, want %+v by %s", record.Move, record.PlayerID, last, before.CurrentPlayerID())
+			}
+			if game.GetStatus() != model.GameStatusInProgress || game.GetWinner() != nil {
+				t.Errorf("after the undo status = %s, winner = %v", game.GetStatus(), game.GetWinner())
+			}
+			if game.CurrentPlayerID() != before.CurrentPlayerID() {
+				t.Errorf("%s is to move after the undo, want %s", game.CurrentPlayerID(), before.CurrentPlayerID())
+			}
+			if game.GetState().(TicTacToeState).Board != before.GetState().(TicTacToeState).Board {
+				t.Errorf("board after the undo differs from the board before the move")
+			}
+			// the undone move can be played again
+			if err := game.MakeMove(record.PlayerID, last); err != nil {
+				t.Errorf("replaying the undone move: %v", err)
+			}
+		})
+	}
+}
+
+func TestUndoMoveWithoutMoves(t *testing.T) {
+	if _, err := newStartedGame(t).UndoMove(); err == nil {
+		t.Error("UndoMove on an empty board returned no error")
+	}
+}
+
+func TestLegalMoves(t *testing.T) {
+	game := newStartedGame(t, Move{0, 0}, Move{1, 1}, Move{2, 2})
+
+	if moves := game.LegalMoves("x"); moves != nil {
+		t.Errorf("LegalMoves for the player waiting = %v, want nil", moves)
+	}
+	moves := game.LegalMoves("o")
+	if len(moves) != 6 {
+		t.Fatalf("LegalMoves = %v, want the 6 open cells", moves)
+	}
+	board := game.GetState().(TicTacToeState).Board
+	for _, move := range moves {
+		if cell := move.(Move); board[cell.Row][cell.Col] != "" {
+			t.Errorf("LegalMoves lists the taken cell %+v", cell)
+		}
+	}
+
+	// nobody has legal moves once the game is over
+	game = newStartedGame(t, Move{0, 0}, Move{1, 0}, Move{0, 1}, Move{1, 1}, Move{0, 2})
+	for _, player := range []string{"x", "o"} {
+		if moves := game.LegalMoves(player); moves != nil {
+			t.Errorf("LegalMoves for %s after the game = %v, want nil", player, moves)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaviraj-j/duoplay/internal/games"
+	"github.com/kaviraj-j/duoplay/internal/middleware"
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+	"github.com/kaviraj-j/duoplay/internal/service"
+)
+
+type MatchHandler struct {
+	matchService *service.MatchService
+}
+
+func NewMatchHandler(matchService *service.MatchService) *MatchHandler {
+	return &MatchHandler{matchService: matchService}
+}
+
+// LoggedInUserMatches returns a page of the user's match history, newest first.
+// The page, page_size and game_type query parameters pick the page and the game
+func (h *MatchHandler) LoggedInUserMatches(c *gin.Context) {
+	user := c.MustGet(middleware.AuthorizationPayloadKey).(*model.User)
+
+	page, err := queryInt(c, "page", 1)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"type": "error", "message": "invalid page"})
+		return
+	}
+	pageSize, err := queryInt(c, "page_size", service.DefaultMatchPageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"type": "error", "message": "invalid page_size"})
+		return
+	}
+	gameType := model.GameType(c.Query("game_type"))
+	if _, exists := games.Lookup(gameType); gameType != "" && !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"type": "error", "message": "unsupported game type"})
+		return
+	}
+
+	matchPage, err := h.matchService.GetUserMatches(c, user.ID, gameType, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"type": "error", "message": "error while fetching matches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"type": "success", "data": matchPage})
+}
+
+// GetMatch returns a finished match with its moves
+func (h *MatchHandler) GetMatch(c *gin.Context) {
+	match, err := h.matchService.GetMatch(c, c.Param("matchID"))
+	if errors.Is(err, repository.ErrMatchNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"type": "error", "message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"type": "error", "message": "error while fetching match"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"type": "success", "data": match})
+}
+
+// queryInt reads an integer query parameter, fallback is used when it is missing
+func queryInt(c *gin.Context, key string, fallback int) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
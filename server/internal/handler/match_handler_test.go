@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaviraj-j/duoplay/internal/games/connectfour"
+	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
+	"github.com/kaviraj-j/duoplay/internal/middleware"
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+	"github.com/kaviraj-j/duoplay/internal/service"
+)
+
+// newMatchRouter serves the match routes for alice, who has played three games of tic tac toe and two of connect four
+func newMatchRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	matchRepo := repository.NewMatchRepository()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	gameTypes := []model.GameType{tictactoe.GameType, connectfour.GameType, tictactoe.GameType, connectfour.GameType, tictactoe.GameType}
+	for i, gameType := range gameTypes {
+		match := model.Match{
+			ID:       fmt.Sprintf("match-%d", i),
+			RoomID:   "room",
+			GameType: gameType,
+			Players: []model.MatchPlayer{
+				{UserID: "alice", Name: "alice", Seat: 0},
+				{UserID: "bob", Name: "bob", Seat: 1},
+			},
+			EndReason: model.EndReasonCompleted,
+			StartedAt: start.Add(time.Duration(i) * time.Hour),
+			EndedAt:   start.Add(time.Duration(i)*time.Hour + time.Minute),
+		}
+		if err := matchRepo.Save(context.Background(), match); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	h := NewMatchHandler(service.NewMatchService(matchRepo))
+	router := gin.New()
+	authenticated := func(c *gin.Context) {
+		c.Set(middleware.AuthorizationPayloadKey, &model.User{ID: "alice", Name: "alice"})
+	}
+	router.GET("/user/me/matches", authenticated, h.LoggedInUserMatches)
+	router.GET("/match/:matchID", authenticated, h.GetMatch)
+	return router
+}
+
+// get serves the request and decodes the data of a successful response into data
+func get(t *testing.T, router *gin.Engine, target string, data any) int {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	if recorder.Code == http.StatusOK {
+		body := struct {
+			Data any `json:"data"`
+		}{Data: data}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decoding %s: %v", recorder.Body.String(), err)
+		}
+	}
+	return recorder.Code
+}
+
+func TestLoggedInUserMatches(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		wantStatus   int
+		wantPage     int
+		wantPageSize int
+		wantTotal    int
+		wantMatches  int
+		// wantGameType is the game of every listed match when the page is filtered
+		wantGameType model.GameType
+	}{
+		{name: "defaults", query: "", wantStatus: http.StatusOK, wantPage: 1, wantPageSize: service.DefaultMatchPageSize, wantTotal: 5, wantMatches: 5},
+		{name: "second page", query: "?page=2&page_size=2", wantStatus: http.StatusOK, wantPage: 2, wantPageSize: 2, wantTotal: 5, wantMatches: 2},
+		{name: "page size clamped", query: "?page_size=1000", wantStatus: http.StatusOK, wantPage: 1, wantPageSize: service.MaxMatchPageSize, wantTotal: 5, wantMatches: 5},
+		{name: "page below one", query: "?page=-3&page_size=0", wantStatus: http.StatusOK, wantPage: 1, wantPageSize: service.DefaultMatchPageSize, wantTotal: 5, wantMatches: 5},
+		{name: "past the last page", query: "?page=9", wantStatus: http.StatusOK, wantPage: 9, wantPageSize: service.DefaultMatchPageSize, wantTotal: 5, wantMatches: 0},
+		{name: "game type filter", query: "?game_type=connectfour", wantStatus: http.StatusOK, wantPage: 1, wantPageSize: service.DefaultMatchPageSize, wantTotal: 2, wantMatches: 2, wantGameType: connectfour.GameType},
+		{name: "unknown game type", query: "?game_type=chess", wantStatus: http.StatusBadRequest},
+		{name: "page not a number", query: "?page=two", wantStatus: http.StatusBadRequest},
+		{name: "page size not a number", query: "?page_size=lots", wantStatus: http.StatusBadRequest},
+	}
+
+	router := newMatchRouter(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var page model.MatchPage
+			status := get(t, router, "/user/me/matches"+tt.query, &page)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", status, tt.wantStatus)
+			}
+			if status != http.StatusOK {
+				return
+			}
+			if page.Page != tt.wantPage || page.PageSize != tt.wantPageSize || page.Total != tt.wantTotal || len(page.Matches) != tt.wantMatches {
+				t.Errorf("page %d of size %d with %d of %d matches, want page %d of size %d with %d of %d",
+					page.Page, page.PageSize, len(page.Matches), page.Total,
+					tt.wantPage, tt.wantPageSize, tt.wantMatches, tt.wantTotal)
+			}
+			for _, match := range page.Matches {
+				if tt.wantGameType != "" && match.GameType != tt.wantGameType {
+					t.Errorf("filtered page has a %s match", match.GameType)
+				}
+			}
+		})
+	}
+}
+
+func TestGetMatch(t *testing.T) {
+	router := newMatchRouter(t)
+
+	var match model.Match
+	if status := get(t, router, "/match/match-3", &match); status != http.StatusOK {
+		t.Fatalf("status = %d, want %d", status, http.StatusOK)
+	}
+	if match.ID != "match-3" || match.GameType != connectfour.GameType || len(match.Players) != 2 {
+		t.Errorf("match = %+v", match)
+	}
+
+	if status := get(t, router, "/match/no-such-match", nil); status != http.StatusNotFound {
+		t.Errorf("unknown match status = %d, want %d", status, http.StatusNotFound)
+	}
+}
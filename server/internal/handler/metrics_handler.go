@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaviraj-j/duoplay/internal/service"
+)
+
+type MetricsHandler struct {
+	metricsService *service.MetricsService
+}
+
+func NewMetricsHandler(s *service.MetricsService) *MetricsHandler {
+	return &MetricsHandler{metricsService: s}
+}
+
+// GetMetrics returns the room event counters
+func (h *MetricsHandler) GetMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"type": "success", "data": h.metricsService.GetMetrics()})
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kaviraj-j/duoplay/internal/middleware"
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+	"github.com/kaviraj-j/duoplay/internal/service"
+)
+
+type ReportHandler struct {
+	reportService *service.ReportService
+}
+
+func NewReportHandler(reportService *service.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+// ListReports returns the reports, filtered by the status query parameter when it is given
+func (h *ReportHandler) ListReports(c *gin.Context) {
+	status := model.ReportStatus(c.Query("status"))
+	reports, err := h.reportService.ListReports(c, status)
+	if errors.Is(err, service.ErrorInvalidReportStatus) {
+		c.JSON(http.StatusBadRequest, gin.H{"type": "error", "message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"type": "error", "message": "error while fetching reports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"type": "success", "data": reports})
+}
+
+// ResolveReport closes a report with the admin's resolution
+func (h *ReportHandler) ResolveReport(c *gin.Context) {
+	var request struct {
+		Resolution string `json:"resolution" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"type": "error", "message": "resolution is required"})
+		return
+	}
+	admin := c.MustGet(middleware.AuthorizationPayloadKey).(*model.User)
+
+	report, err := h.reportService.ResolveReport(c, c.Param("reportID"), admin.ID, request.Resolution)
+	switch {
+	case errors.Is(err, repository.ErrReportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"type": "error", "message": err.Error()})
+		return
+	case errors.Is(err, service.ErrorReportResolved):
+		c.JSON(http.StatusConflict, gin.H{"type": "error", "message": err.Error()})
+		return
+	case errors.Is(err, service.ErrorResolutionRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"type": "error", "message": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"type": "error", "message": "error while resolving report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"type": "success", "data": report})
+}
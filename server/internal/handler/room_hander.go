@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -8,7 +10,9 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/kaviraj-j/duoplay/internal/middleware"
 	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
 	"github.com/kaviraj-j/duoplay/internal/service"
+	"github.com/kaviraj-j/duoplay/internal/ws"
 )
 
 // WebSocket message structs
@@ -21,9 +25,10 @@ type WSMessage struct {
 type RoomHandler struct {
 	roomService *service.RoomService
 	upgrader    websocket.Upgrader
+	wsConfig    ws.Config
 }
 
-func NewRoomHandler(s *service.RoomService) *RoomHandler {
+func NewRoomHandler(s *service.RoomService, wsConfig ws.Config) *RoomHandler {
 	return &RoomHandler{
 		roomService: s,
 		upgrader: websocket.Upgrader{
@@ -31,45 +36,45 @@ func NewRoomHandler(s *service.RoomService) *RoomHandler {
 				return true // TODO: need to implement a proper origin checking
 			},
 		},
+		wsConfig: wsConfig,
 	}
 }
 
+// upgrade switches the request to a WebSocket connection that is safe to write from any goroutine
+func (h *RoomHandler) upgrade(c *gin.Context) (*ws.Conn, error) {
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ws.NewConn(conn, h.wsConfig), nil
+}
+
 // NewRoom creates a new game room
 func (h *RoomHandler) NewRoom(c *gin.Context) {
-	room, err := h.roomService.CreateRoom(c)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"type":    "error",
-			"message": "Failed to create room",
-			"data":    nil,
-		})
+	userInterface, exists := c.Get(middleware.AuthorizationPayloadKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"type": "error", "message": "Unauthorized", "data": nil})
 		return
 	}
+	user := userInterface.(*model.User)
 
 	// upgrade http connection to websocket
-	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := h.upgrade(c)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"type": "error", "message": "Could not upgrade connection", "data": nil})
 		return
 	}
 
-	userInterface, exists := c.Get(middleware.AuthorizationPayloadKey)
-	if !exists {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Unauthorized", Data: nil})
-		conn.Close()
-		return
-	}
-	user := userInterface.(*model.User)
-
-	// create player and add to room
+	// create player and a room hosted by them
 	player := model.Player{
 		User: *user,
 		Conn: conn,
 	}
 
-	if err := h.roomService.AddPlayer(c, room.ID, player); err != nil {
+	room, err := h.roomService.CreateRoom(c, player)
+	if err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to create room", Data: nil})
 		conn.Close()
-		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
 		return
 	}
 
@@ -77,10 +82,11 @@ func (h *RoomHandler) NewRoom(c *gin.Context) {
 	conn.WriteJSON(WSMessage{
 		Type:    "room_created",
 		Message: "Room created successfully",
-		Data:    room.GetRoomResponse(),
+		Data:    room,
 	})
 
-	go h.handleWebSocketMessages(c, conn, room.ID, player)
+	// the connection is served for as long as it stays open
+	h.handleWebSocketMessages(c, conn, room.ID, player)
 }
 
 // JoinRoom handles player joining a room via WebSocket
@@ -100,7 +106,7 @@ func (h *RoomHandler) JoinRoom(c *gin.Context) {
 	user := userInterface.(*model.User)
 
 	// Upgrade HTTP connection to WebSocket
-	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := h.upgrade(c)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"type": "error", "message": "Could not upgrade connection", "data": nil})
 		return
@@ -112,24 +118,13 @@ func (h *RoomHandler) JoinRoom(c *gin.Context) {
 		Conn: conn,
 	}
 
-	room, err := h.roomService.GetRoom(c, roomID)
-	if err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Room not found", Data: nil})
-		conn.Close()
-		return
-	}
-
-	if err := h.roomService.AddPlayer(c, roomID, player); err != nil {
-		conn.Close()
-		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
-		return
-	}
-
-	// change room status to game selection
-	room.Status = model.RoomStatusGameSelection
-	err = h.roomService.UpdateRoom(c, *room)
+	room, err := h.roomService.JoinRoom(c, roomID, player)
 	if err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to update room", Data: nil})
+		if errors.Is(err, repository.ErrRoomNotFound) {
+			conn.WriteJSON(WSMessage{Type: "error", Message: "Room not found", Data: nil})
+		} else {
+			conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
+		}
 		conn.Close()
 		return
 	}
@@ -139,24 +134,60 @@ func (h *RoomHandler) JoinRoom(c *gin.Context) {
 	conn.WriteJSON(WSMessage{
 		Type:    "joined_room",
 		Message: "Joined room successfully",
-		Data:    room.GetRoomResponse(),
+		Data:    room,
 	})
+	// players who join late or come back see what was said
+	h.roomService.SendChatHistory(c, roomID, player.User.ID, conn)
+
+	// Handle WebSocket connection for as long as it stays open
+	h.handleWebSocketMessages(c, conn, roomID, player)
+}
+
+// WatchRoom lets a user who is not playing follow a room via WebSocket
+func (h *RoomHandler) WatchRoom(c *gin.Context) {
+	roomID := c.Param("roomID")
+	if roomID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"type": "error", "message": "Room ID is required", "data": nil})
+		return
+	}
 
-	// Send message to the other player
-	var otherPlayer model.Player
-	for userId, p := range room.Players {
-		if userId != user.ID {
-			otherPlayer = p
-			break
+	userInterface, exists := c.Get(middleware.AuthorizationPayloadKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"type": "error", "message": "Unauthorized", "data": nil})
+		return
+	}
+	user := userInterface.(*model.User)
+
+	conn, err := h.upgrade(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"type": "error", "message": "Could not upgrade connection", "data": nil})
+		return
+	}
+
+	spectator := model.Spectator{
+		User: *user,
+		Conn: conn,
+	}
+	room, err := h.roomService.WatchRoom(c, roomID, spectator)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoomNotFound) {
+			conn.WriteJSON(WSMessage{Type: "error", Message: "Room not found", Data: nil})
+		} else {
+			conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
 		}
+		conn.Close()
+		return
 	}
-	otherPlayer.Conn.WriteJSON(WSMessage{
-		Type:    "joined_room",
-		Message: "Opponent has joined room",
+
+	conn.WriteJSON(WSMessage{
+		Type:    "watching_room",
+		Message: "Watching room",
+		Data:    room,
 	})
+	h.roomService.SendChatHistory(c, roomID, spectator.User.ID, conn)
 
-	// Handle WebSocket connection
-	go h.handleWebSocketMessages(c, conn, roomID, player)
+	// spectators only receive, the connection is served for as long as it stays open
+	h.handleSpectatorMessages(c, conn, roomID, spectator)
 }
 
 func (h *RoomHandler) JoinWaitingQueue(c *gin.Context) {
@@ -168,20 +199,26 @@ func (h *RoomHandler) JoinWaitingQueue(c *gin.Context) {
 	user := userInterface.(*model.User)
 
 	// Upgrade HTTP connection to WebSocket
-	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := h.upgrade(c)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"type": "error", "message": "Could not upgrade connection", "data": nil})
 		return
 	}
 
+	// the game to be matched on, players who leave it out are matched for any game
+	gameType := model.GameType(c.DefaultQuery("game_type", string(model.AnyGame)))
+
 	// Add user to queue with WebSocket connection
-	if err := h.roomService.JoinQueue(c, user.ID, conn); err != nil {
-		conn.Close()
+	matched, err := h.roomService.JoinQueue(c, user.ID, gameType, conn)
+	if err != nil {
 		if err == service.ErrorUserAlreadyInQueue {
 			conn.WriteJSON(WSMessage{Type: "error", Message: "User is already in queue", Data: nil})
+		} else if err == service.ErrorUnsupportedGame {
+			conn.WriteJSON(WSMessage{Type: "error", Message: "Unsupported game type", Data: nil})
 		} else {
 			conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to join queue", Data: nil})
 		}
+		conn.Close()
 		return
 	}
 
@@ -192,12 +229,17 @@ func (h *RoomHandler) JoinWaitingQueue(c *gin.Context) {
 		Data:    nil,
 	})
 
-	// Handle queue WebSocket connection
-	go h.handleQueueConnection(c, conn, user.ID)
+	// Handle queue WebSocket connection for as long as it stays open
+	player := model.Player{
+		User: *user,
+		Conn: conn,
+	}
+	h.handleQueueConnection(c, conn, player, matched)
 }
 
-// handleQueueConnection handles WebSocket communication for queue waiting
-func (h *RoomHandler) handleQueueConnection(ctx *gin.Context, conn *websocket.Conn, userID string) {
+// handleQueueConnection handles WebSocket communication for queue waiting, once the player is
+// matched the same connection carries on as the connection to their room
+func (h *RoomHandler) handleQueueConnection(ctx *gin.Context, conn *ws.Conn, player model.Player, matched <-chan string) {
 	defer conn.Close()
 
 	// Send initial message
@@ -205,29 +247,115 @@ func (h *RoomHandler) handleQueueConnection(ctx *gin.Context, conn *websocket.Co
 		Type:    "queue_joined",
 		Message: "Waiting for an opponent to connect...",
 	})
+	// the new player and everyone already waiting get their place in the queue
+	h.roomService.NotifyQueuePositions(ctx)
 
 	for {
-		// Read message from WebSocket
-		messageType, _, err := conn.ReadMessage()
+		// Read message from WebSocket, a client that stops answering pings fails here too
+		messageType, msgBytes, err := conn.ReadMessage()
 		if err != nil {
-			// Handle disconnection - remove from queue
-			h.roomService.RemoveFromQueue(ctx, userID)
+			// Handle disconnection - remove from queue, a player who was just taken off the
+			// queue gets their match anyway and is treated as disconnected from the room
+			h.roomService.RemoveFromQueue(ctx, player.User.ID)
+			if roomID, ok := <-matched; ok {
+				h.roomService.HandlePlayerDisconnected(ctx, roomID, player)
+			}
 			return
 		}
 
+		select {
+		case roomID, ok := <-matched:
+			if !ok {
+				// the player left the queue without a match
+				return
+			}
+			// the player was matched while waiting, this message is their first in the room
+			h.handleWebSocketMessage(ctx, conn, roomID, player, msgBytes)
+			h.handleWebSocketMessages(ctx, conn, roomID, player)
+			return
+		default:
+		}
+
 		// Handle different message types
 		switch messageType {
 		case websocket.TextMessage:
-			// Handle text messages if needed
+			// a player who takes up a bot offer leaves the queue for the bot's room
+			if roomID, ok := h.handleQueueMessage(ctx, conn, player, matched, msgBytes); ok {
+				h.handleWebSocketMessages(ctx, conn, roomID, player)
+				return
+			}
 			continue
 		case websocket.CloseMessage:
 			// Remove from queue on close
-			h.roomService.RemoveFromQueue(ctx, userID)
+			h.roomService.RemoveFromQueue(ctx, player.User.ID)
 			return
 		}
 	}
 }
 
+// handleQueueMessage handles a message from a queued player, it returns the room ID once the player
+// has accepted a bot offer
+func (h *RoomHandler) handleQueueMessage(ctx *gin.Context, conn *ws.Conn, player model.Player, matched <-chan string, msgBytes []byte) (string, bool) {
+	var msg map[string]interface{}
+	if err := json.Unmarshal(msgBytes, &msg); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: "Invalid message format", Data: nil})
+		return "", false
+	}
+	if msgType, _ := msg["type"].(string); model.MessageType(msgType) != model.MessageTypeAcceptBot {
+		return "", false
+	}
+
+	difficulty, _ := msg["difficulty"].(string)
+	if err := h.roomService.AcceptBotOffer(ctx, player.User.ID, model.BotDifficulty(difficulty)); err != nil {
+		if errors.Is(err, service.ErrorNoBotOffer) || errors.Is(err, service.ErrorInvalidBotDifficulty) {
+			conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
+		}
+		return "", false
+	}
+	roomID, ok := <-matched
+	return roomID, ok
+}
+
+// PlayBot starts a game against a bot, the game_type and difficulty query parameters pick the game
+// and how well the bot plays. The player is sent the start_game message of the new room
+func (h *RoomHandler) PlayBot(c *gin.Context) {
+	userInterface, exists := c.Get(middleware.AuthorizationPayloadKey)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"type": "error", "message": "Unauthorized", "data": nil})
+		return
+	}
+	user := userInterface.(*model.User)
+
+	conn, err := h.upgrade(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"type": "error", "message": "Could not upgrade connection", "data": nil})
+		return
+	}
+
+	// without a game type the bot picks a game it plays
+	gameType := model.GameType(c.Query("game_type"))
+	difficulty := model.BotDifficulty(c.Query("difficulty"))
+
+	player := model.Player{
+		User: *user,
+		Conn: conn,
+	}
+	room, err := h.roomService.CreateBotRoom(c, player, gameType, difficulty)
+	if err != nil {
+		if errors.Is(err, service.ErrorUnsupportedGame) {
+			conn.WriteJSON(WSMessage{Type: "error", Message: "No bot plays this game", Data: nil})
+		} else if errors.Is(err, service.ErrorInvalidBotDifficulty) {
+			conn.WriteJSON(WSMessage{Type: "error", Message: "Unknown bot difficulty", Data: nil})
+		} else {
+			conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to create room", Data: nil})
+		}
+		conn.Close()
+		return
+	}
+
+	h.handleWebSocketMessages(c, conn, room.ID, player)
+}
+
 // GetRoom returns room details
 func (h *RoomHandler) GetRoom(c *gin.Context) {
 	roomID := c.Param("roomID")
@@ -236,13 +364,13 @@ func (h *RoomHandler) GetRoom(c *gin.Context) {
 		return
 	}
 
-	room, err := h.roomService.GetRoom(c, roomID)
+	room, err := h.roomService.GetRoomResponse(c, roomID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"type": "error", "message": "Room not found", "data": nil})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"type": "success", "message": "Room fetched successfully", "data": room.GetRoomResponse()})
+	c.JSON(http.StatusOK, gin.H{"type": "success", "message": "Room fetched successfully", "data": room})
 }
 
 // StartGame initiates the game in the room
@@ -289,21 +417,12 @@ func (h *RoomHandler) LeaveRoom(c *gin.Context) {
 		return
 	}
 	user := userInterface.(*model.User)
-	// Get the other user's connection
-	room, _ := h.roomService.GetRoom(c, roomID)
-	oppositePlayer, err := h.roomService.GetOppositePlayer(c, room.Players, user.ID)
 
-	if err := h.roomService.LeaveRoom(c, roomID); err != nil {
+	// the other player is told by the room service before the room is closed
+	if err := h.roomService.LeaveRoom(c, roomID, user.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"type": "error", "message": "Failed to leave room", "data": nil})
 		return
 	}
 
-	if err == nil {
-		oppositePlayer.Conn.WriteJSON(WSMessage{
-			Type:    "opponent_left",
-			Message: "Opponent has left the room",
-		})
-	}
-
 	c.JSON(http.StatusOK, gin.H{"type": "success", "message": "Left room", "data": nil})
 }
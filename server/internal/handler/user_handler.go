@@ -1,20 +1,24 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kaviraj-j/duoplay/internal/middleware"
+	"github.com/kaviraj-j/duoplay/internal/model"
 	"github.com/kaviraj-j/duoplay/internal/service"
 )
 
 type UserHandler struct {
-	userService service.UserService
+	userService   service.UserService
+	ratingService *service.RatingService
 }
 
-func NewUserHandler(userService *service.UserService) *UserHandler {
+func NewUserHandler(userService *service.UserService, ratingService *service.RatingService) *UserHandler {
 	return &UserHandler{
-		userService: *userService,
+		userService:   *userService,
+		ratingService: ratingService,
 	}
 }
 
@@ -31,6 +35,13 @@ func (handler *UserHandler) NewUser(ctx *gin.Context) {
 	}
 
 	user, token, err := handler.userService.RegisterUser(ctx, newUserRequestDetails.Name)
+	if errors.Is(err, service.ErrorNameNotAllowed) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"type":    "error",
+			"message": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"type":    "error",
@@ -48,15 +59,26 @@ func (handler *UserHandler) NewUser(ctx *gin.Context) {
 }
 
 func (handler *UserHandler) LoggedInUserDetails(ctx *gin.Context) {
-	user, ok := ctx.Get(middleware.AuthorizationPayloadKey)
+	userInterface, ok := ctx.Get(middleware.AuthorizationPayloadKey)
 	if !ok {
 		ctx.JSON(http.StatusUnauthorized, gin.H{
 			"type":    "error",
 			"message": "user is not authorized",
 		})
+		return
+	}
+	user := userInterface.(*model.User)
+
+	ratings, err := handler.ratingService.GetUserRatings(ctx, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"type":    "error",
+			"message": "error while fetching ratings",
+		})
+		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
 		"type": "success",
-		"data": user,
+		"data": model.UserProfile{User: *user, Ratings: ratings},
 	})
 }
@@ -4,76 +4,124 @@ import (
 	"encoding/json"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
 	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/ws"
 )
 
-// handleWebSocketMessages handles WebSocket communication for a game session
-func (h *RoomHandler) handleWebSocketMessages(c *gin.Context, conn *websocket.Conn, roomID string, player model.Player) {
+// handleWebSocketMessages handles WebSocket communication for a game session,
+// it runs until the connection is closed and every command is applied by the room's actor
+func (h *RoomHandler) handleWebSocketMessages(c *gin.Context, conn *ws.Conn, roomID string, player model.Player) {
 	defer conn.Close()
 
 	for {
 		// Read message from WebSocket
 		_, msgBytes, err := conn.ReadMessage()
 		if err != nil {
+			// the client closed the connection or stopped answering pings
+			h.roomService.HandlePlayerDisconnected(c, roomID, player)
+			return
+		}
+		h.handleWebSocketMessage(c, conn, roomID, player, msgBytes)
+	}
+}
+
+// handleSpectatorMessages serves a spectator's connection until it closes, spectators cannot play
+// so every message they send other than chat and reports is rejected
+func (h *RoomHandler) handleSpectatorMessages(c *gin.Context, conn *ws.Conn, roomID string, spectator model.Spectator) {
+	defer conn.Close()
+
+	for {
+		_, msgBytes, err := conn.ReadMessage()
+		if err != nil {
+			h.roomService.RemoveSpectator(c, roomID, spectator)
 			return
 		}
 
-		// Parse message as JSON
 		var msg map[string]interface{}
 		if err := json.Unmarshal(msgBytes, &msg); err != nil {
 			conn.WriteJSON(WSMessage{Type: "error", Message: "Invalid message format", Data: nil})
 			continue
 		}
-		typeStr, ok := msg["type"].(string)
-		if !ok {
-			conn.WriteJSON(WSMessage{Type: "error", Message: "Missing or invalid message type", Data: nil})
-			continue
-		}
-
-		typeVal := model.MessageType(typeStr)
-		switch typeVal {
-		// when a player joins a room
-		case model.MessageTypeJoinRoom:
-			h.handlePlayerJoinedRoom(c, conn, roomID, player)
-		case model.MessageTypeChooseGame:
-			h.handleGameChosen(c, conn, roomID, player, msg)
-		case model.MessageTypeGameAccept:
-			h.handleGameAccepted(c, conn, roomID, player, msg)
-		case model.MessageTypeGameReject:
-			h.handleGameRejected(c, conn, roomID, player, msg)
-		case model.MessageTypeGameMove:
-			h.handleGameMove(c, conn, roomID, player, msg)
-		case model.MessageTypeReplayGame:
-			h.handleReplayGame(c, conn, roomID, player, msg)
-		case model.MessageTypeReplayAccepted:
-			h.handleReplayAccepted(c, conn, roomID, player, msg)
-		case model.MessageTypeReplayRejected:
-			h.handleReplayRejected(c, conn, roomID, player, msg)
+		msgType, _ := msg["type"].(string)
+		switch model.MessageType(msgType) {
+		case model.MessageTypeChatMessage:
+			h.handleChatMessage(c, conn, roomID, spectator.User, msg)
+		case model.MessageTypeReportPlayer:
+			h.handleReportPlayer(c, conn, roomID, spectator.User, msg)
 		default:
-			conn.WriteJSON(WSMessage{Type: "error", Message: "Unknown message type", Data: nil})
+			conn.WriteJSON(WSMessage{Type: "error", Message: "Spectators cannot send game messages", Data: nil})
 		}
 	}
 }
 
-// handlePlayerJoinedRoom handles the player joining a room
-func (h *RoomHandler) handlePlayerJoinedRoom(c *gin.Context, conn *websocket.Conn, roomID string, player model.Player) {
-	// check if the room is valid
-	room, err := h.roomService.GetRoom(c, roomID)
-	if err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Room not found", Data: nil})
+// handleWebSocketMessage parses a single message from a player and hands it to its handler
+func (h *RoomHandler) handleWebSocketMessage(c *gin.Context, conn *ws.Conn, roomID string, player model.Player, msgBytes []byte) {
+	// Parse message as JSON
+	var msg map[string]interface{}
+	if err := json.Unmarshal(msgBytes, &msg); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: "Invalid message format", Data: nil})
 		return
 	}
-
-	// check if the player is in the room
-	_, exists := room.Players[player.User.ID]
-	if !exists {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Player not found in room", Data: nil})
+	typeStr, ok := msg["type"].(string)
+	if !ok {
+		conn.WriteJSON(WSMessage{Type: "error", Message: "Missing or invalid message type", Data: nil})
 		return
 	}
 
+	typeVal := model.MessageType(typeStr)
+	switch typeVal {
+	// when a player joins a room
+	case model.MessageTypeJoinRoom:
+		h.handlePlayerJoinedRoom(c, conn, roomID, player)
+	case model.MessageTypeChooseGame:
+		h.handleGameChosen(c, conn, roomID, player, msg)
+	case model.MessageTypeGameAccept:
+		h.handleGameAccepted(c, conn, roomID, player, msg)
+	case model.MessageTypeGameReject:
+		h.handleGameRejected(c, conn, roomID, player, msg)
+	case model.MessageTypeGameMove:
+		h.handleGameMove(c, conn, roomID, player, msg)
+	case model.MessageTypeReplayGame:
+		h.handleReplayGame(c, conn, roomID, player, msg)
+	case model.MessageTypeReplayAccepted:
+		h.handleReplayAccepted(c, conn, roomID, player, msg)
+	case model.MessageTypeReplayRejected:
+		h.handleReplayRejected(c, conn, roomID, player, msg)
+	case model.MessageTypeSetSeatPolicy:
+		h.handleSetSeatPolicy(c, conn, roomID, player, msg)
+	case model.MessageTypeResume:
+		h.handleResume(c, conn, roomID, player, msg)
+	case model.MessageTypeRequestHint:
+		h.handleRequestHint(c, conn, roomID, player)
+	case model.MessageTypeResign:
+		h.handleResign(c, conn, roomID, player)
+	case model.MessageTypeOfferDraw:
+		h.handleOfferDraw(c, conn, roomID, player)
+	case model.MessageTypeAcceptDraw:
+		h.handleAcceptDraw(c, conn, roomID, player)
+	case model.MessageTypeDeclineDraw:
+		h.handleDeclineDraw(c, conn, roomID, player)
+	case model.MessageTypeRequestUndo:
+		h.handleRequestUndo(c, conn, roomID, player)
+	case model.MessageTypeAcceptUndo:
+		h.handleAcceptUndo(c, conn, roomID, player)
+	case model.MessageTypeDeclineUndo:
+		h.handleDeclineUndo(c, conn, roomID, player)
+	case model.MessageTypeChatMessage:
+		h.handleChatMessage(c, conn, roomID, player.User, msg)
+	case model.MessageTypeMuteOpponent:
+		h.handleMuteOpponent(c, conn, roomID, player, msg)
+	case model.MessageTypeReportPlayer:
+		h.handleReportPlayer(c, conn, roomID, player.User, msg)
+	default:
+		conn.WriteJSON(WSMessage{Type: "error", Message: "Unknown message type", Data: nil})
+	}
+}
+
+// handlePlayerJoinedRoom handles the player joining a room
+func (h *RoomHandler) handlePlayerJoinedRoom(c *gin.Context, conn *ws.Conn, roomID string, player model.Player) {
 	// Use the service method to handle player joined room
-	if err := h.roomService.HandlePlayerJoinedRoom(c, room, player); err != nil {
+	if err := h.roomService.HandlePlayerJoinedRoom(c, roomID, player); err != nil {
 		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to notify other players", Data: nil})
 		return
 	}
@@ -83,14 +131,7 @@ func (h *RoomHandler) handlePlayerJoinedRoom(c *gin.Context, conn *websocket.Con
 }
 
 // handleGameChosen handles a player choosing a game
-func (h *RoomHandler) handleGameChosen(c *gin.Context, conn *websocket.Conn, roomID string, player model.Player, msg map[string]interface{}) {
-	// check if the room is valid
-	room, err := h.roomService.GetRoom(c, roomID)
-	if err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Room not found", Data: nil})
-		return
-	}
-
+func (h *RoomHandler) handleGameChosen(c *gin.Context, conn *ws.Conn, roomID string, player model.Player, msg map[string]interface{}) {
 	// Get the game type from the parsed message
 	gameTypeStr, ok := msg["game_type"].(string)
 	if !ok {
@@ -100,8 +141,22 @@ func (h *RoomHandler) handleGameChosen(c *gin.Context, conn *websocket.Conn, roo
 
 	gameType := model.GameType(gameTypeStr)
 
+	// the time control is optional, a game chosen without one is played untimed
+	var timeControl model.TimeControl
+	if raw, ok := msg["time_control"]; ok {
+		timeControlBytes, _ := json.Marshal(raw)
+		if err := json.Unmarshal(timeControlBytes, &timeControl); err != nil {
+			conn.WriteJSON(WSMessage{Type: "error", Message: "Invalid time control", Data: nil})
+			return
+		}
+		if err := timeControl.Validate(); err != nil {
+			conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
+			return
+		}
+	}
+
 	// Handle the game choice through the service
-	if err := h.roomService.HandleGameChosen(c, room, player, gameType); err != nil {
+	if err := h.roomService.HandleGameChosen(c, roomID, player, gameType, timeControl); err != nil {
 		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to handle game choice", Data: nil})
 		return
 	}
@@ -111,20 +166,14 @@ func (h *RoomHandler) handleGameChosen(c *gin.Context, conn *websocket.Conn, roo
 		Type:    "game_chosen_confirmation",
 		Message: "Your game choice has been recorded",
 		Data: map[string]interface{}{
-			"game_type": gameType,
+			"game_type":    gameType,
+			"time_control": timeControl,
 		},
 	})
 }
 
 // handleGameAccepted handles a player accepting a game
-func (h *RoomHandler) handleGameAccepted(c *gin.Context, conn *websocket.Conn, roomID string, player model.Player, msg map[string]interface{}) {
-	// check if the room is valid
-	room, err := h.roomService.GetRoom(c, roomID)
-	if err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Room not found", Data: nil})
-		return
-	}
-
+func (h *RoomHandler) handleGameAccepted(c *gin.Context, conn *ws.Conn, roomID string, player model.Player, msg map[string]interface{}) {
 	// Get the game type from the parsed message
 	gameTypeStr, ok := msg["game_type"].(string)
 	if !ok {
@@ -135,7 +184,7 @@ func (h *RoomHandler) handleGameAccepted(c *gin.Context, conn *websocket.Conn, r
 	gameType := model.GameType(gameTypeStr)
 
 	// Handle the game choice through the service
-	if err := h.roomService.HandleGameAccepted(c, room, player, gameType); err != nil {
+	if err := h.roomService.HandleGameAccepted(c, roomID, player, gameType); err != nil {
 		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to handle game accepted", Data: nil})
 		return
 	}
@@ -143,14 +192,7 @@ func (h *RoomHandler) handleGameAccepted(c *gin.Context, conn *websocket.Conn, r
 }
 
 // handleGameRejected handles a player rejecting a game
-func (h *RoomHandler) handleGameRejected(c *gin.Context, conn *websocket.Conn, roomID string, player model.Player, msg map[string]interface{}) {
-	// check if the room is valid
-	room, err := h.roomService.GetRoom(c, roomID)
-	if err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Room not found", Data: nil})
-		return
-	}
-
+func (h *RoomHandler) handleGameRejected(c *gin.Context, conn *ws.Conn, roomID string, player model.Player, msg map[string]interface{}) {
 	// Get the game type from the parsed message
 	gameTypeStr, ok := msg["game_type"].(string)
 	if !ok {
@@ -161,121 +203,205 @@ func (h *RoomHandler) handleGameRejected(c *gin.Context, conn *websocket.Conn, r
 	gameType := model.GameType(gameTypeStr)
 
 	// Handle the game choice through the service
-	if err := h.roomService.HandleGameRejected(c, room, player, gameType); err != nil {
+	if err := h.roomService.HandleGameRejected(c, roomID, player, gameType); err != nil {
 		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to handle game rejected", Data: nil})
 		return
 	}
 }
 
 // handleGameMove handles a player making a move in the game
-func (h *RoomHandler) handleGameMove(c *gin.Context, conn *websocket.Conn, roomID string, player model.Player, msg map[string]interface{}) {
-	room, err := h.roomService.GetRoom(c, roomID)
-	if err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Room not found", Data: nil})
+func (h *RoomHandler) handleGameMove(c *gin.Context, conn *ws.Conn, roomID string, player model.Player, msg map[string]interface{}) {
+	move, ok := msg["move"]
+	if !ok {
+		conn.WriteJSON(WSMessage{Type: "error", Message: "Move is required", Data: nil})
 		return
 	}
 
-	_, exists := room.Players[player.User.ID]
-	if !exists {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Player not found in room", Data: nil})
+	// The move is applied and broadcast to both players by the room service
+	if err := h.roomService.HandleGameMove(c, roomID, player, move); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
 		return
 	}
+}
 
-	if room.Game == nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Game not started", Data: nil})
+func (h *RoomHandler) handleReplayGame(c *gin.Context, conn *ws.Conn, roomID string, player model.Player, msg map[string]interface{}) {
+	if err := h.roomService.HandleReplayGame(c, roomID, player, msg); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to handle replay game", Data: nil})
 		return
 	}
 
-	move, ok := msg["move"]
-	if !ok {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Move is required", Data: nil})
-		return
-	}
+	conn.WriteJSON(WSMessage{
+		Type:    "replay_game_received",
+		Message: "Replay game received, processing...",
+		Data:    nil,
+	})
+}
 
-	// Make the move
-	if err := room.Game.MakeMove(player.User.ID, move); err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
+func (h *RoomHandler) handleReplayAccepted(c *gin.Context, conn *ws.Conn, roomID string, player model.Player, msg map[string]interface{}) {
+	if err := h.roomService.HandleReplayAccepted(c, roomID, player, msg); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to handle replay accepted", Data: nil})
 		return
 	}
+}
 
-	// Update room status if game is over
-	if room.Game.IsGameOver() {
-		room.Status = model.RoomStatusGameOver
-		roomResponse := room.GetRoomResponse()
-		room.EventChannel <- model.Event{
-			Type:    model.RoomEventTypeGameOver,
-			Payload: roomResponse,
-		}
+func (h *RoomHandler) handleReplayRejected(c *gin.Context, conn *ws.Conn, roomID string, player model.Player, msg map[string]interface{}) {
+	if err := h.roomService.HandleReplayRejected(c, roomID, player, msg); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to handle replay rejected", Data: nil})
+		return
 	}
+}
 
-	// Update room in repository
-	if err := h.roomService.UpdateRoom(c, *room); err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to update room", Data: nil})
+// handleSetSeatPolicy handles the host changing how seats are assigned
+func (h *RoomHandler) handleSetSeatPolicy(c *gin.Context, conn *ws.Conn, roomID string, player model.Player, msg map[string]interface{}) {
+	policyStr, ok := msg["policy"].(string)
+	if !ok {
+		conn.WriteJSON(WSMessage{Type: "error", Message: "Seat policy is required", Data: nil})
 		return
 	}
+	// first_player_id is only used by the host_chooses policy
+	firstPlayerID, _ := msg["first_player_id"].(string)
 
-	// Get updated room response with game state
-	roomResponse := room.GetRoomResponse()
-
-	// Broadcast the move to both players
-	for _, p := range room.Players {
-		if p.Conn != nil {
-			p.Conn.WriteJSON(WSMessage{
-				Type:    string(model.MessageTypeMoveMade),
-				Message: "Move made",
-				Data:    roomResponse,
-			})
-		}
+	if err := h.roomService.HandleSetSeatPolicy(c, roomID, player, model.SeatPolicy(policyStr), firstPlayerID); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
+		return
 	}
 }
 
-func (h *RoomHandler) handleReplayGame(c *gin.Context, conn *websocket.Conn, roomID string, player model.Player, msg map[string]interface{}) {
-	room, err := h.roomService.GetRoom(c, roomID)
-	if err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Room not found", Data: nil})
+// handleResume sends a reconnecting player what they missed since the last seq they saw
+func (h *RoomHandler) handleResume(c *gin.Context, conn *ws.Conn, roomID string, player model.Player, msg map[string]interface{}) {
+	// last_seq is 0 for a client that has not seen any event yet
+	lastSeq, _ := msg["last_seq"].(float64)
+	if lastSeq < 0 {
+		conn.WriteJSON(WSMessage{Type: "error", Message: "Invalid last_seq", Data: nil})
 		return
 	}
 
-	_, exists := room.Players[player.User.ID]
-	if !exists {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Player not found in room", Data: nil})
+	if err := h.roomService.ResumeSession(c, roomID, player, uint64(lastSeq)); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to resume session", Data: nil})
 		return
 	}
+}
 
-	if err := h.roomService.HandleReplayGame(c, room, player, msg); err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to handle replay game", Data: nil})
+// handleRequestHint sends the player a suggested move, only the requesting player gets it
+func (h *RoomHandler) handleRequestHint(c *gin.Context, conn *ws.Conn, roomID string, player model.Player) {
+	move, err := h.roomService.RequestHint(c, roomID, player)
+	if err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
 		return
 	}
 
 	conn.WriteJSON(WSMessage{
-		Type:    "replay_game_received",
-		Message: "Replay game received, processing...",
-		Data:    nil,
+		Type:    string(model.MessageTypeHint),
+		Message: "Suggested move",
+		Data: map[string]interface{}{
+			"move": move,
+		},
 	})
 }
 
-func (h *RoomHandler) handleReplayAccepted(c *gin.Context, conn *websocket.Conn, roomID string, player model.Player, msg map[string]interface{}) {
-	room, err := h.roomService.GetRoom(c, roomID)
-	if err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Room not found", Data: nil})
+// handleResign concedes the round, both players get the final room
+func (h *RoomHandler) handleResign(c *gin.Context, conn *ws.Conn, roomID string, player model.Player) {
+	if err := h.roomService.HandleResign(c, roomID, player); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
+	}
+}
+
+// handleOfferDraw passes a draw offer on to the opponent
+func (h *RoomHandler) handleOfferDraw(c *gin.Context, conn *ws.Conn, roomID string, player model.Player) {
+	if err := h.roomService.HandleOfferDraw(c, roomID, player); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
 		return
 	}
 
-	if err := h.roomService.HandleReplayAccepted(c, room, player, msg); err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to handle replay accepted", Data: nil})
+	conn.WriteJSON(WSMessage{Type: "draw_offer_sent", Message: "Draw offer sent", Data: nil})
+}
+
+// handleAcceptDraw ends the round as an agreed draw
+func (h *RoomHandler) handleAcceptDraw(c *gin.Context, conn *ws.Conn, roomID string, player model.Player) {
+	if err := h.roomService.HandleAcceptDraw(c, roomID, player); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
+	}
+}
+
+// handleDeclineDraw turns down the opponent's draw offer
+func (h *RoomHandler) handleDeclineDraw(c *gin.Context, conn *ws.Conn, roomID string, player model.Player) {
+	if err := h.roomService.HandleDeclineDraw(c, roomID, player); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
+	}
+}
+
+// handleRequestUndo asks the opponent to let the player take back their last move
+func (h *RoomHandler) handleRequestUndo(c *gin.Context, conn *ws.Conn, roomID string, player model.Player) {
+	if err := h.roomService.HandleRequestUndo(c, roomID, player); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
+	}
+}
+
+// handleAcceptUndo takes back the opponent's last move, both players get the room after it
+func (h *RoomHandler) handleAcceptUndo(c *gin.Context, conn *ws.Conn, roomID string, player model.Player) {
+	if err := h.roomService.HandleAcceptUndo(c, roomID, player); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
+	}
+}
+
+// handleDeclineUndo turns down the opponent's undo request
+func (h *RoomHandler) handleDeclineUndo(c *gin.Context, conn *ws.Conn, roomID string, player model.Player) {
+	if err := h.roomService.HandleDeclineUndo(c, roomID, player); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
+	}
+}
+
+// handleChatMessage passes a chat message on to the room, the sender gets it back like everyone else
+func (h *RoomHandler) handleChatMessage(c *gin.Context, conn *ws.Conn, roomID string, user model.User, msg map[string]interface{}) {
+	text, ok := msg["text"].(string)
+	if !ok {
+		conn.WriteJSON(WSMessage{Type: "error", Message: "Chat text is required", Data: nil})
 		return
 	}
+
+	if err := h.roomService.HandleChatMessage(c, roomID, user, text); err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
+	}
 }
 
-func (h *RoomHandler) handleReplayRejected(c *gin.Context, conn *websocket.Conn, roomID string, player model.Player, msg map[string]interface{}) {
-	room, err := h.roomService.GetRoom(c, roomID)
+// handleMuteOpponent mutes or unmutes the opponent's chat, without muted it toggles
+func (h *RoomHandler) handleMuteOpponent(c *gin.Context, conn *ws.Conn, roomID string, player model.Player, msg map[string]interface{}) {
+	var muted *bool
+	if value, ok := msg["muted"].(bool); ok {
+		muted = &value
+	}
+
+	isMuted, err := h.roomService.HandleMuteOpponent(c, roomID, player, muted)
 	if err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Room not found", Data: nil})
+		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
 		return
 	}
 
-	if err := h.roomService.HandleReplayRejected(c, room, player, msg); err != nil {
-		conn.WriteJSON(WSMessage{Type: "error", Message: "Failed to handle replay rejected", Data: nil})
+	conn.WriteJSON(WSMessage{
+		Type:    "opponent_muted",
+		Message: "Opponent chat setting updated",
+		Data: map[string]interface{}{
+			"muted": isMuted,
+		},
+	})
+}
+
+// handleReportPlayer files a report about someone in the room, without offender_id a player reports their opponent
+func (h *RoomHandler) handleReportPlayer(c *gin.Context, conn *ws.Conn, roomID string, user model.User, msg map[string]interface{}) {
+	offenderID, _ := msg["offender_id"].(string)
+	reason, _ := msg["reason"].(string)
+
+	report, err := h.roomService.ReportPlayer(c, roomID, user, offenderID, reason)
+	if err != nil {
+		conn.WriteJSON(WSMessage{Type: "error", Message: err.Error(), Data: nil})
 		return
 	}
+
+	conn.WriteJSON(WSMessage{
+		Type:    "player_reported",
+		Message: "Thanks, a moderator will look into your report",
+		Data: map[string]interface{}{
+			"report_id": report.ID,
+		},
+	})
 }
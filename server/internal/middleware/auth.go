@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kaviraj-j/duoplay/internal/model"
 	"github.com/kaviraj-j/duoplay/internal/service"
 )
 
@@ -88,3 +89,27 @@ func (authMiddleware *AuthMiddleWare) IsAuthenticated() gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// IsAdmin lets only the admins through, it must run after IsAuthenticated
+func (authMiddleware *AuthMiddleWare) IsAdmin() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userInterface, ok := ctx.Get(AuthorizationPayloadKey)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"type":    "error",
+				"message": "user is not authorized",
+			})
+			return
+		}
+
+		user := userInterface.(*model.User)
+		if !authMiddleware.userService.IsAdmin(user.ID) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"type":    "error",
+				"message": "admin access required",
+			})
+			return
+		}
+		ctx.Next()
+	}
+}
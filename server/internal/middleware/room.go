@@ -24,17 +24,17 @@ func (roomMiddleware *RoomMiddleWare) IsRoomOwner() gin.HandlerFunc {
 		userPayload, _ := ctx.Get(AuthorizationPayloadKey)
 		user := userPayload.(*model.User)
 
-		room, err := roomMiddleware.roomService.GetRoom(ctx, roomID)
+		isPlayer, err := roomMiddleware.roomService.IsPlayerInRoom(ctx, roomID, user.ID)
 		if err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{"type": "error", "message": "Room not found"})
 			ctx.Abort()
+			return
 		}
 
-		_, exists := room.Players[user.ID]
-
-		if !exists {
+		if !isPlayer {
 			ctx.JSON(http.StatusForbidden, gin.H{"type": "error", "message": "You are not a player in this room"})
 			ctx.Abort()
+			return
 		}
 
 		ctx.Next()
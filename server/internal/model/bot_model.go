@@ -0,0 +1,35 @@
+package model
+
+// BotDifficulty is how well a bot plays
+type BotDifficulty string
+
+const (
+	// BotDifficultyRandom plays any legal move
+	BotDifficultyRandom BotDifficulty = "random"
+	// BotDifficultyGreedy takes a winning move or blocks the opponent's, otherwise plays a good looking move
+	BotDifficultyGreedy BotDifficulty = "greedy"
+	// BotDifficultyPerfect searches the game to the end and never misses a win or a draw
+	BotDifficultyPerfect BotDifficulty = "perfect"
+)
+
+func (d BotDifficulty) IsValid() bool {
+	switch d {
+	case BotDifficultyRandom, BotDifficultyGreedy, BotDifficultyPerfect:
+		return true
+	}
+	return false
+}
+
+// Bot plays a seat of a room on the server, it has no connection and moves when it is its turn
+type Bot interface {
+	Difficulty() BotDifficulty
+	// ChooseMove returns the move playerID should make in the game's current state, it must not change the game
+	ChooseMove(game Game, playerID string) (any, error)
+}
+
+// TurnBasedGame is implemented by games that know whose turn it is, bots only play games that do
+type TurnBasedGame interface {
+	Game
+	// CurrentPlayerID returns the player to move, empty when nobody is
+	CurrentPlayerID() string
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+const (
+	// ChatHistorySize is how many of the latest chat messages a room keeps for players who join later
+	ChatHistorySize = 50
+	// MaxChatMessageLength is the longest chat message in characters
+	MaxChatMessageLength = 500
+)
+
+// ChatMessage is a line of chat in a room
+type ChatMessage struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	Text   string `json:"text"`
+	// Spectator is set for messages from spectators
+	Spectator bool      `json:"spectator,omitempty"`
+	SentAt    time.Time `json:"sent_at"`
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// TimeControl is how much time the players get in a round, the zero value plays without a clock.
+// A round is played either with a bank of time per player, topped up by the increment after every
+// move, or with a fixed limit for every move
+type TimeControl struct {
+	InitialSeconds   int `json:"initial_seconds,omitempty"`
+	IncrementSeconds int `json:"increment_seconds,omitempty"`
+	PerMoveSeconds   int `json:"per_move_seconds,omitempty"`
+}
+
+func (t TimeControl) IsZero() bool {
+	return t == TimeControl{}
+}
+
+func (t TimeControl) Validate() error {
+	if t.InitialSeconds < 0 || t.IncrementSeconds < 0 || t.PerMoveSeconds < 0 {
+		return errors.New("time control values cannot be negative")
+	}
+	if t.PerMoveSeconds > 0 && (t.InitialSeconds > 0 || t.IncrementSeconds > 0) {
+		return errors.New("a per-move limit cannot be combined with a time bank")
+	}
+	if t.IncrementSeconds > 0 && t.InitialSeconds == 0 {
+		return errors.New("an increment needs an initial time")
+	}
+	return nil
+}
+
+// ClockState is the players' clocks in a timed round
+type ClockState struct {
+	TimeControl TimeControl `json:"time_control"`
+	// RemainingMs is every player's time left when the running clock was started, keyed by player ID
+	RemainingMs map[string]int64 `json:"remaining_ms"`
+	// Running is the player whose clock is running, empty when both clocks are stopped
+	Running string `json:"running,omitempty"`
+	// TurnStartedAt is when the running clock was started, clients count down from it
+	TurnStartedAt time.Time `json:"turn_started_at"`
+}
+
+func NewClockState(timeControl TimeControl, playerIDs []string) *ClockState {
+	clock := &ClockState{
+		TimeControl: timeControl,
+		RemainingMs: make(map[string]int64, len(playerIDs)),
+	}
+	for _, id := range playerIDs {
+		clock.RemainingMs[id] = int64(timeControl.InitialSeconds) * 1000
+	}
+	return clock
+}
+
+// Remaining returns the time the player had left when the running clock was started
+func (c *ClockState) Remaining(playerID string) time.Duration {
+	return time.Duration(c.RemainingMs[playerID]) * time.Millisecond
+}
+
+// Copy returns a clock that shares nothing with c
+func (c *ClockState) Copy() *ClockState {
+	clock := *c
+	clock.RemainingMs = make(map[string]int64, len(c.RemainingMs))
+	for id, remaining := range c.RemainingMs {
+		clock.RemainingMs[id] = remaining
+	}
+	return &clock
+}
@@ -0,0 +1,38 @@
+package model
+
+import "testing"
+
+func TestTimeControlValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		timeControl TimeControl
+		wantErr     bool
+	}{
+		{"untimed", TimeControl{}, false},
+		{"bank", TimeControl{InitialSeconds: 300}, false},
+		{"bank with increment", TimeControl{InitialSeconds: 300, IncrementSeconds: 5}, false},
+		{"per move", TimeControl{PerMoveSeconds: 30}, false},
+		{"negative", TimeControl{InitialSeconds: -1}, true},
+		{"per move with a bank", TimeControl{InitialSeconds: 300, PerMoveSeconds: 30}, true},
+		{"increment without a bank", TimeControl{IncrementSeconds: 5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.timeControl.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClockStateCopy(t *testing.T) {
+	clock := NewClockState(TimeControl{InitialSeconds: 60}, []string{"alice", "bob"})
+	if clock.RemainingMs["alice"] != 60000 || clock.RemainingMs["bob"] != 60000 {
+		t.Fatalf("new clock = %v", clock.RemainingMs)
+	}
+	copied := clock.Copy()
+	copied.RemainingMs["alice"] = 0
+	if clock.RemainingMs["alice"] != 60000 {
+		t.Error("changing the copy changed the original clock")
+	}
+}
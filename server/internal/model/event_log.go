@@ -0,0 +1,76 @@
+package model
+
+import "encoding/json"
+
+// EventLogCapacity is how many of the latest room events are kept for clients that resume
+const EventLogCapacity = 128
+
+// LoggedEvent is a message sent to the players of a room, stored as it was sent
+type LoggedEvent struct {
+	Seq uint64
+	// To is the player the message was sent to, empty when it went to every player
+	To      string
+	Payload json.RawMessage
+}
+
+// EventLog numbers the messages sent to a room's players and keeps the latest of them,
+// a client that reconnects sends the last seq it saw and is sent what it missed
+type EventLog struct {
+	LastSeq  uint64
+	entries  []LoggedEvent
+	capacity int
+}
+
+func NewEventLog(capacity int) *EventLog {
+	if capacity <= 0 {
+		capacity = EventLogCapacity
+	}
+	return &EventLog{
+		entries:  make([]LoggedEvent, 0, capacity),
+		capacity: capacity,
+	}
+}
+
+// Append stamps msg with the next seq and stores it, the oldest event is dropped once the log is full
+func (l *EventLog) Append(to string, msg map[string]interface{}) (LoggedEvent, error) {
+	seq := l.LastSeq + 1
+	msg["seq"] = seq
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		delete(msg, "seq")
+		return LoggedEvent{}, err
+	}
+
+	l.LastSeq = seq
+	event := LoggedEvent{Seq: seq, To: to, Payload: payload}
+	if len(l.entries) == l.capacity {
+		copy(l.entries, l.entries[1:])
+		l.entries = l.entries[:len(l.entries)-1]
+	}
+	l.entries = append(l.entries, event)
+	return event, nil
+}
+
+// Since returns the events after seq that were sent to the player, ok is false when
+// some of them are no longer in the log or seq was never handed out
+func (l *EventLog) Since(playerID string, seq uint64) (events []LoggedEvent, ok bool) {
+	if seq > l.LastSeq {
+		return nil, false
+	}
+	if seq == l.LastSeq {
+		return nil, true
+	}
+	if len(l.entries) == 0 || l.entries[0].Seq > seq+1 {
+		return nil, false
+	}
+
+	for _, event := range l.entries {
+		if event.Seq <= seq {
+			continue
+		}
+		if event.To == "" || event.To == playerID {
+			events = append(events, event)
+		}
+	}
+	return events, true
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func appendEvents(t *testing.T, log *EventLog, recipients ...string) {
+	t.Helper()
+	for _, to := range recipients {
+		if _, err := log.Append(to, map[string]interface{}{"type": "test"}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+}
+
+func seqs(events []LoggedEvent) []uint64 {
+	out := make([]uint64, 0, len(events))
+	for _, event := range events {
+		out = append(out, event.Seq)
+	}
+	return out
+}
+
+func TestEventLogAppendStampsSeq(t *testing.T) {
+	log := NewEventLog(4)
+	msg := map[string]interface{}{"type": "move_made"}
+	event, err := log.Append("", msg)
+	if err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if event.Seq != 1 || log.LastSeq != 1 {
+		t.Fatalf("first event seq %d, log last seq %d, want 1", event.Seq, log.LastSeq)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload["seq"] != float64(1) || payload["type"] != "move_made" {
+		t.Errorf("payload = %v", payload)
+	}
+
+	// a message that cannot be encoded takes no seq
+	if _, err := log.Append("", map[string]interface{}{"bad": make(chan int)}); err == nil {
+		t.Fatal("Append of an unencodable message did not fail")
+	}
+	if log.LastSeq != 1 {
+		t.Errorf("failed append moved the last seq to %d", log.LastSeq)
+	}
+}
+
+func TestEventLogSince(t *testing.T) {
+	log := NewEventLog(4)
+	// seqs 1..6, the log keeps 3..6
+	appendEvents(t, log, "", "alice", "bob", "", "alice", "bob")
+
+	tests := []struct {
+		name     string
+		playerID string
+		seq      uint64
+		want     []uint64
+		wantOK   bool
+	}{
+		{"up to date", "alice", 6, nil, true},
+		{"missed the broadcast and own events", "alice", 3, []uint64{4, 5}, true},
+		{"other player's view", "bob", 3, []uint64{4, 6}, true},
+		{"oldest kept event is still replayable", "bob", 2, []uint64{3, 4, 6}, true},
+		{"events dropped from the log", "alice", 1, nil, false},
+		{"seq never handed out", "alice", 7, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, ok := log.Since(tt.playerID, tt.seq)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			got := seqs(events)
+			if len(got) != len(tt.want) {
+				t.Fatalf("seqs = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("seqs = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEventLogSinceOnEmptyLog(t *testing.T) {
+	log := NewEventLog(0)
+	if events, ok := log.Since("alice", 0); !ok || len(events) != 0 {
+		t.Errorf("Since(0) on an empty log = %v, %v", events, ok)
+	}
+	if _, ok := log.Since("alice", 1); ok {
+		t.Error("Since(1) on an empty log reported ok")
+	}
+}
@@ -10,13 +10,9 @@ const (
 	GameStatusOver       GameStatus = "over"
 )
 
+// GameType identifies a game, every game package declares its own value when it registers
 type GameType string
 
-const (
-	TicTacToeGame GameType = "tictactoe"
-	// TODO: add new games to the list
-)
-
 // Game interface defines core game behavior
 type Game interface {
 	GetType() GameType
@@ -27,6 +23,42 @@ type Game interface {
 	GetWinner() *Player
 	GetStatus() GameStatus
 	ResetState() error
+	// SeatPlayers seats the players in turn order, players[0] takes the first seat
+	SeatPlayers(players []Player) error
+	GetSeats() []Seat
+}
+
+// MoveLister is implemented by games that can enumerate the moves a player may make
+type MoveLister interface {
+	// LegalMoves returns the moves playerID may make now, none when it is not their turn or the game is not running
+	LegalMoves(playerID string) []any
+}
+
+// Cloner is implemented by games that can copy themselves, the copy shares no state with the original
+type Cloner interface {
+	Clone() Game
+}
+
+// Undoer is implemented by games that keep their move history and can take moves back
+type Undoer interface {
+	// History returns the moves of the round in the order they were made
+	History() []MoveRecord
+	// UndoMove takes back the last move, the player who made it is to move again
+	UndoMove() (MoveRecord, error)
+}
+
+// MoveRecord is a move in a game's history, Move is the game's own move type
+type MoveRecord struct {
+	PlayerID string `json:"player_id"`
+	Move     any    `json:"move"`
+}
+
+// Seat is a player's place in a game, Index is the turn order and Mark is the
+// symbol or colour the game draws for that seat
+type Seat struct {
+	Index    int    `json:"index"`
+	Mark     string `json:"mark"`
+	PlayerID string `json:"player_id"`
 }
 
 // GameState holds common game state
@@ -40,7 +72,24 @@ type GameState struct {
 	CreatedAt time.Time
 }
 
+// Clone copies the state, the players map and winner included
+func (s *GameState) Clone() *GameState {
+	clone := *s
+	clone.Players = make(map[string]Player, len(s.Players))
+	for id, p := range s.Players {
+		clone.Players[id] = p
+	}
+	if s.Winner != nil {
+		winner := *s.Winner
+		clone.Winner = &winner
+	}
+	return &clone
+}
+
 type GameListPayload struct {
 	Name        string `json:"name"`
 	DisplayName string `json:"display_name"`
+	Description string `json:"description"`
+	MinPlayers  int    `json:"min_players"`
+	MaxPlayers  int    `json:"max_players"`
 }
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Match is the record of a finished round, kept for the players' match history
+type Match struct {
+	ID        string        `json:"id"`
+	RoomID    string        `json:"room_id"`
+	GameType  GameType      `json:"game_type"`
+	Players   []MatchPlayer `json:"players"`
+	Moves     []MatchMove   `json:"moves"`
+	WinnerID  string        `json:"winner_id,omitempty"`
+	EndReason EndReason     `json:"end_reason"`
+	StartedAt time.Time     `json:"started_at"`
+	EndedAt   time.Time     `json:"ended_at"`
+}
+
+// MatchPlayer is a player of a match in the seat they played from
+type MatchPlayer struct {
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	// Seat is the turn order, the player in seat 0 moved first
+	Seat  int    `json:"seat"`
+	Mark  string `json:"mark,omitempty"`
+	IsBot bool   `json:"is_bot,omitempty"`
+}
+
+// MatchMove is a move of a match, in the order the moves were played
+type MatchMove struct {
+	PlayerID string    `json:"player_id"`
+	Move     any       `json:"move"`
+	PlayedAt time.Time `json:"played_at"`
+}
+
+// MatchPage is a page of a player's match history, Total counts the matches on every page
+type MatchPage struct {
+	Matches  []Match `json:"matches"`
+	Page     int     `json:"page"`
+	PageSize int     `json:"page_size"`
+	Total    int     `json:"total"`
+}
@@ -0,0 +1,10 @@
+package model
+
+// Metrics counts what happened in the rooms since the server started
+type Metrics struct {
+	StartedAt     string                   `json:"started_at"`
+	Events        map[RoomEventType]uint64 `json:"events"`
+	GamesFinished map[GameType]uint64      `json:"games_finished"`
+	EndReasons    map[EndReason]uint64     `json:"end_reasons"`
+	ActiveRooms   int                      `json:"active_rooms"`
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/ws"
+)
+
+// MessageTypeQueueStatus tells a waiting player where they are in the queue
+const MessageTypeQueueStatus MessageType = "queue_status"
+
+// AnyGame is the queue of players who will play whatever game their opponent wants
+const AnyGame GameType = "any"
+
+// QueueEntry is a player waiting for a match
+type QueueEntry struct {
+	UserID string
+	// GameType is the game the player wants to play, or AnyGame
+	GameType   GameType
+	Conn       *ws.Conn
+	EnqueuedAt time.Time
+	// Rating is what the player is matched by in their queue, Ratings holds their rating in every game
+	Rating  int
+	Ratings map[GameType]int
+	// Matched receives the room ID once the player is matched, it is closed when the player
+	// leaves the queue without a match
+	Matched chan string
+}
+
+// RatingFor returns the player's rating in the game, or their queue rating for a game they have no rating in
+func (e QueueEntry) RatingFor(gameType GameType) int {
+	if rating, ok := e.Ratings[gameType]; ok {
+		return rating
+	}
+	return e.Rating
+}
+
+// QueuePair is two players taken off the queue to play each other, GameType is AnyGame
+// when neither of them asked for a specific game
+type QueuePair struct {
+	First    QueueEntry
+	Second   QueueEntry
+	GameType GameType
+}
+
+// QueueStatus is sent to each waiting player whenever the queue changes
+type QueueStatus struct {
+	GameType GameType `json:"game_type"`
+	// Position is 1 for the player who has waited longest for the same game
+	Position      int       `json:"position"`
+	QueueSize     int       `json:"queue_size"`
+	EnqueuedAt    time.Time `json:"enqueued_at"`
+	WaitedSeconds int       `json:"waited_seconds"`
+	// EstimatedWaitSeconds is left out until enough matches were made to estimate it
+	EstimatedWaitSeconds *int `json:"estimated_wait_seconds,omitempty"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// DefaultRating is the rating a player starts with in every game
+const DefaultRating = 1200
+
+// Rating is a player's Elo rating in one game
+type Rating struct {
+	UserID    string    `json:"user_id"`
+	GameType  GameType  `json:"game_type"`
+	Rating    int       `json:"rating"`
+	Games     int       `json:"games"`
+	Wins      int       `json:"wins"`
+	Losses    int       `json:"losses"`
+	Draws     int       `json:"draws"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewRating(userID string, gameType GameType) Rating {
+	return Rating{
+		UserID:   userID,
+		GameType: gameType,
+		Rating:   DefaultRating,
+	}
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// ReportStatus tells whether a report still needs a moderator
+type ReportStatus string
+
+const (
+	ReportStatusOpen     ReportStatus = "open"
+	ReportStatusResolved ReportStatus = "resolved"
+)
+
+func (s ReportStatus) IsValid() bool {
+	return s == ReportStatusOpen || s == ReportStatusResolved
+}
+
+// MaxReportReasonLength is the longest reason a report can give in characters
+const MaxReportReasonLength = 500
+
+// Report is a player's complaint about someone in a room, with what was said and played before it was made
+type Report struct {
+	ID         string `json:"id"`
+	RoomID     string `json:"room_id"`
+	ReporterID string `json:"reporter_id"`
+	OffenderID string `json:"offender_id"`
+	Reason     string `json:"reason"`
+	// Chat and Moves are the room's chat and the current round's moves when the report was made
+	Chat      []ChatMessage `json:"chat"`
+	Moves     []MoveRecord  `json:"moves"`
+	Status    ReportStatus  `json:"status"`
+	CreatedAt time.Time     `json:"created_at"`
+
+	// set once a moderator resolves the report
+	Resolution string     `json:"resolution,omitempty"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
@@ -1,8 +1,10 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
-	"github.com/gorilla/websocket"
+	"github.com/kaviraj-j/duoplay/internal/ws"
 )
 
 // MessageType is the type for WebSocket message types
@@ -32,6 +34,64 @@ const (
 	MessageTypeAuth            MessageType = "auth"
 	MessageTypeRoomCreated     MessageType = "room_created"
 	MessageTypeQueueJoined     MessageType = "queue_joined"
+	MessageTypeSetSeatPolicy   MessageType = "set_seat_policy"
+	MessageTypeSeatPolicySet   MessageType = "seat_policy_set"
+	MessageTypeOpponentLeft    MessageType = "opponent_left"
+	// MessageTypeOpponentDisconnected is sent when the other player's connection drops without leaving the room
+	MessageTypeOpponentDisconnected MessageType = "opponent_disconnected"
+	// MessageTypeResume asks for the events after the client's last seen seq
+	MessageTypeResume MessageType = "resume"
+	// MessageTypeResumed follows the replayed events once the client has caught up
+	MessageTypeResumed MessageType = "resumed"
+	// MessageTypeSnapshot carries the whole room when the missed events are no longer in the log
+	MessageTypeSnapshot MessageType = "snapshot"
+	// MessageTypeOpponentReconnected is sent when a disconnected opponent is back before their grace period ran out
+	MessageTypeOpponentReconnected MessageType = "opponent_reconnected"
+	// MessageTypeGameOver is sent when a round ends for a reason other than a move, such as a forfeit or a timeout
+	MessageTypeGameOver MessageType = "game_over"
+	// MessageTypeBotOffer offers a player who has waited long in the queue a game against a bot
+	MessageTypeBotOffer MessageType = "bot_offer"
+	// MessageTypeAcceptBot takes up a bot offer, the player leaves the queue for a room with the bot
+	MessageTypeAcceptBot MessageType = "accept_bot"
+	// MessageTypeRequestHint asks for a suggested move, hints are only given in practice rooms
+	MessageTypeRequestHint MessageType = "request_hint"
+	// MessageTypeHint answers MessageTypeRequestHint with the suggested move
+	MessageTypeHint MessageType = "hint"
+	// MessageTypeResign concedes the round to the opponent
+	MessageTypeResign MessageType = "resign"
+	// MessageTypeOfferDraw offers the opponent a draw, they answer with accept_draw or decline_draw
+	MessageTypeOfferDraw MessageType = "offer_draw"
+	// MessageTypeDrawOffered tells a player their opponent offers a draw
+	MessageTypeDrawOffered MessageType = "draw_offered"
+	MessageTypeAcceptDraw  MessageType = "accept_draw"
+	MessageTypeDeclineDraw MessageType = "decline_draw"
+	// MessageTypeDrawDeclined tells a player their draw offer was turned down
+	MessageTypeDrawDeclined MessageType = "draw_declined"
+	// MessageTypeRequestUndo asks the opponent to let the player take back their last move
+	MessageTypeRequestUndo MessageType = "request_undo"
+	// MessageTypeUndoRequested tells a player their opponent wants to take back a move
+	MessageTypeUndoRequested MessageType = "undo_requested"
+	MessageTypeAcceptUndo    MessageType = "accept_undo"
+	MessageTypeDeclineUndo   MessageType = "decline_undo"
+	// MessageTypeUndoDeclined tells a player their undo request was turned down
+	MessageTypeUndoDeclined MessageType = "undo_declined"
+	// MessageTypeMoveUndone carries the room after a move was taken back
+	MessageTypeMoveUndone MessageType = "move_undone"
+	// MessageTypeSpectatorJoined and MessageTypeSpectatorLeft carry the new spectator count to the room
+	MessageTypeSpectatorJoined MessageType = "spectator_joined"
+	MessageTypeSpectatorLeft   MessageType = "spectator_left"
+	// MessageTypeRoomClosed tells spectators the room is gone
+	MessageTypeRoomClosed MessageType = "room_closed"
+	// MessageTypeSeatTaken is sent on a spectator's watch connection before it is closed because they joined as a player
+	MessageTypeSeatTaken MessageType = "seat_taken"
+	// MessageTypeChatMessage is a chat line, sent by a player or spectator and delivered to the room
+	MessageTypeChatMessage MessageType = "chat_message"
+	// MessageTypeChatHistory carries the room's recent chat to a player or spectator who joins
+	MessageTypeChatHistory MessageType = "chat_history"
+	// MessageTypeMuteOpponent turns the chat from the player's opponent off or back on
+	MessageTypeMuteOpponent MessageType = "mute_opponent"
+	// MessageTypeReportPlayer reports someone in the room to the moderators
+	MessageTypeReportPlayer MessageType = "report_player"
 )
 
 type RoomStatus string
@@ -46,32 +106,148 @@ const (
 
 type Player struct {
 	User User
-	Conn *websocket.Conn
+	Conn *ws.Conn
+	// Bot plays the seat for a bot player, who never has a connection
+	Bot Bot
+}
+
+// Spectator watches a room without a seat, they get every broadcast but cannot play
+type Spectator struct {
+	User User
+	Conn *ws.Conn
 }
 
 // GameSelectionState tracks which players have chosen games
 type GameSelectionState struct {
 	PlayerChoices map[string]GameType `json:"player_choices"` // playerID -> gameType
+	// TimeControls is the time control each player proposed with their game, playerID -> time control
+	TimeControls map[string]TimeControl `json:"time_controls"`
+}
+
+// SeatPolicy decides which player takes the first seat when a round starts
+type SeatPolicy string
+
+const (
+	// SeatPolicyRandom draws the seat order from a seed recorded on the room
+	SeatPolicyRandom SeatPolicy = "random"
+	// SeatPolicyAlternate draws the first round and swaps the seats on every replay
+	SeatPolicyAlternate SeatPolicy = "alternate"
+	// SeatPolicyLoserStarts lets the loser of the previous round move first, draws alternate
+	SeatPolicyLoserStarts SeatPolicy = "loser_starts"
+	// SeatPolicyHostChooses lets the room host pick who moves first
+	SeatPolicyHostChooses SeatPolicy = "host_chooses"
+)
+
+func (p SeatPolicy) IsValid() bool {
+	switch p {
+	case SeatPolicyRandom, SeatPolicyAlternate, SeatPolicyLoserStarts, SeatPolicyHostChooses:
+		return true
+	}
+	return false
+}
+
+// SeatingState records how the seats of a room were assigned so every round can be reproduced
+type SeatingState struct {
+	Policy SeatPolicy `json:"policy"`
+	HostID string     `json:"host_id"`
+	// FirstPlayerID is the host's pick for SeatPolicyHostChooses
+	FirstPlayerID string `json:"first_player_id,omitempty"`
+	// Round counts the rounds played in the room, starting at 1
+	Round int `json:"round"`
+	// Seed is the random seed the current round's seat order was drawn with
+	Seed int64 `json:"seed"`
+	// Order is the seat order of the current round as player IDs
+	Order []string `json:"order"`
+	// LastWinnerID is the winner of the previous round, empty for a draw
+	LastWinnerID string `json:"last_winner_id,omitempty"`
+}
+
+// EndReason records why a round ended
+type EndReason string
+
+const (
+	// EndReasonCompleted is a round decided on the board, a win or a draw
+	EndReasonCompleted EndReason = "completed"
+	// EndReasonForfeit is a round lost by a player who did not reconnect within the grace period
+	EndReasonForfeit EndReason = "forfeit"
+	// EndReasonResignation is a round conceded by a player
+	EndReasonResignation EndReason = "resignation"
+	// EndReasonAgreement is a draw both players agreed to
+	EndReasonAgreement EndReason = "agreement"
+	// EndReasonTimeout is a round lost by a player whose clock ran out
+	EndReasonTimeout EndReason = "timeout"
+)
+
+// RoundResult is the outcome of a finished round, WinnerID is empty for a draw
+type RoundResult struct {
+	WinnerID string    `json:"winner_id,omitempty"`
+	Reason   EndReason `json:"reason"`
 }
 
+// RoomEventType is the kind of event a room publishes on the event bus
 type RoomEventType string
 
 const (
-	RoomEventTypeGameOver RoomEventType = "game_over"
+	RoomEventTypePlayerJoined RoomEventType = "player_joined"
+	RoomEventTypePlayerLeft   RoomEventType = "player_left"
+	RoomEventTypeGameStarted  RoomEventType = "game_started"
+	RoomEventTypeMoveMade     RoomEventType = "move_made"
+	RoomEventTypeGameOver     RoomEventType = "game_over"
+	RoomEventTypeMoveUndone   RoomEventType = "move_undone"
+	// RoomEventTypeRoomClosed is published once the room is gone, however it was closed
+	RoomEventTypeRoomClosed RoomEventType = "room_closed"
 )
 
+// Event is something that happened in a room, published for the parts of the server outside the room
 type Event struct {
-	Type    RoomEventType `json:"type"`
-	Payload interface{}   `json:"payload"`
+	Type   RoomEventType `json:"type"`
+	RoomID string        `json:"room_id"`
+	// PlayerID is the player the event is about, empty for events about the whole room
+	PlayerID string `json:"player_id,omitempty"`
+	// Room is the state of the room right after the event, empty for RoomEventTypeRoomClosed
+	Room RoomResponse `json:"room"`
+	// Payload holds event specific data, the move for RoomEventTypeMoveMade, the
+	// moves taken back for RoomEventTypeMoveUndone and the Match for RoomEventTypeGameOver
+	Payload interface{} `json:"payload,omitempty"`
+	Time    time.Time   `json:"time"`
 }
 
 type Room struct {
-	ID            string             `json:"id"`
-	Players       map[string]Player  `json:"players"`
-	Game          Game               `json:"game"`
-	GameSelection GameSelectionState `json:"game_selection"`
-	Status        RoomStatus         `json:"status"`
-	EventChannel  chan Event         `json:"event_channel"`
+	ID      string            `json:"id"`
+	Players map[string]Player `json:"players"`
+	// Spectators watch the room, keyed by user ID, they are never players
+	Spectators    map[string]Spectator `json:"-"`
+	Game          Game                 `json:"game"`
+	GameSelection GameSelectionState   `json:"game_selection"`
+	Seating       SeatingState         `json:"seating"`
+	Status        RoomStatus           `json:"status"`
+	// Events numbers and keeps the messages sent to the players so they can resume after reconnecting
+	Events *EventLog `json:"-"`
+	// Result is set once the current round is over
+	Result *RoundResult `json:"result,omitempty"`
+	// RoundStartedAt and RoundMoves make up the match record of the current round, undone moves are dropped
+	RoundStartedAt time.Time   `json:"-"`
+	RoundMoves     []MatchMove `json:"-"`
+	// DrawOfferedBy is the player whose draw offer is waiting for an answer, a move withdraws it
+	DrawOfferedBy string `json:"draw_offered_by,omitempty"`
+	// UndoRequestedBy is the player whose undo request is waiting for an answer, a move withdraws it
+	UndoRequestedBy string `json:"undo_requested_by,omitempty"`
+	// ReplayRequestedBy is the player whose replay request is waiting for an answer, set once the round is over
+	ReplayRequestedBy string `json:"replay_requested_by,omitempty"`
+	// UndosUsed counts the undos every player has had this round, keyed by player ID
+	UndosUsed map[string]int `json:"undos_used"`
+	// TimeControl is the time control agreed with the game, it applies to every round
+	TimeControl TimeControl `json:"time_control"`
+	// Clock is the clocks of the current round, nil for untimed rounds
+	Clock *ClockState `json:"clock,omitempty"`
+	// ClockTimer ends the round when the running clock runs out
+	ClockTimer *time.Timer `json:"-"`
+	// Chat holds the latest chat messages, oldest first
+	Chat []ChatMessage `json:"-"`
+	// MutedOpponent holds the players who muted their opponent's chat
+	MutedOpponent map[string]bool `json:"-"`
+	// DisconnectTimers forfeit the round for players who do not reconnect in time, keyed by player ID
+	DisconnectTimers map[string]*time.Timer `json:"-"`
 }
 
 type RoomPlayer struct {
@@ -83,27 +259,64 @@ type RoomResponse struct {
 	Players       map[string]RoomPlayer `json:"players"`
 	Status        RoomStatus            `json:"status"`
 	GameSelection map[string]GameType   `json:"game_selection"`
+	Seating       SeatingState          `json:"seating"`
 	Game          *GameResponse         `json:"game,omitempty"`
+	// LastSeq is the seq of the latest event sent in the room, a client resumes from it
+	LastSeq uint64       `json:"last_seq"`
+	Result  *RoundResult `json:"result,omitempty"`
+	// DrawOfferedBy is the player whose draw offer is waiting for an answer
+	DrawOfferedBy string `json:"draw_offered_by,omitempty"`
+	// UndoRequestedBy is the player whose undo request is waiting for an answer
+	UndoRequestedBy string `json:"undo_requested_by,omitempty"`
+	// ReplayRequestedBy is the player whose replay request is waiting for an answer
+	ReplayRequestedBy string         `json:"replay_requested_by,omitempty"`
+	UndosUsed         map[string]int `json:"undos_used,omitempty"`
+	TimeControl       TimeControl    `json:"time_control"`
+	Clock             *ClockState    `json:"clock,omitempty"`
+	SpectatorCount    int            `json:"spectator_count"`
+	// Practice is set for rooms with a bot, they leave ratings alone and give hints
+	Practice bool `json:"practice,omitempty"`
 }
 
 type GameResponse struct {
 	Type   GameType    `json:"type"`
 	Status GameStatus  `json:"status"`
 	State  interface{} `json:"state"`
+	Seats  []Seat      `json:"seats"`
+	// LegalMoves are the moves the player to move may make, for games that can list them
+	LegalMoves []any `json:"legal_moves,omitempty"`
 }
 
 func NewRoom() Room {
 	return Room{
-		ID:      uuid.New().String(),
-		Players: make(map[string]Player),
+		ID:         uuid.New().String(),
+		Players:    make(map[string]Player),
+		Spectators: make(map[string]Spectator),
 		GameSelection: GameSelectionState{
 			PlayerChoices: make(map[string]GameType),
+			TimeControls:  make(map[string]TimeControl),
 		},
-		Status:       RoomStatusWaitingForPlayer,
-		EventChannel: make(chan Event),
+		Seating: SeatingState{
+			Policy: SeatPolicyRandom,
+		},
+		Status:           RoomStatusWaitingForPlayer,
+		Events:           NewEventLog(EventLogCapacity),
+		DisconnectTimers: make(map[string]*time.Timer),
+		UndosUsed:        make(map[string]int),
+		MutedOpponent:    make(map[string]bool),
 	}
 }
 
+// IsPractice reports whether a bot plays one of the seats
+func (r Room) IsPractice() bool {
+	for _, p := range r.Players {
+		if p.Bot != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (r Room) GetRoomResponse() RoomResponse {
 	players := make(map[string]RoomPlayer)
 	for id, p := range r.Players {
@@ -112,11 +325,36 @@ func (r Room) GetRoomResponse() RoomResponse {
 		}
 	}
 
+	// the response is handed to other goroutines, so it must not share maps with the room
+	gameSelection := make(map[string]GameType, len(r.GameSelection.PlayerChoices))
+	for id, gameType := range r.GameSelection.PlayerChoices {
+		gameSelection[id] = gameType
+	}
+	undosUsed := make(map[string]int, len(r.UndosUsed))
+	for id, used := range r.UndosUsed {
+		undosUsed[id] = used
+	}
+
 	response := RoomResponse{
-		ID:            r.ID,
-		Players:       players,
-		Status:        r.Status,
-		GameSelection: r.GameSelection.PlayerChoices,
+		ID:                r.ID,
+		Players:           players,
+		Status:            r.Status,
+		GameSelection:     gameSelection,
+		Seating:           r.Seating,
+		Result:            r.Result,
+		DrawOfferedBy:     r.DrawOfferedBy,
+		UndoRequestedBy:   r.UndoRequestedBy,
+		ReplayRequestedBy: r.ReplayRequestedBy,
+		UndosUsed:         undosUsed,
+		TimeControl:       r.TimeControl,
+		SpectatorCount:    len(r.Spectators),
+		Practice:          r.IsPractice(),
+	}
+	if r.Events != nil {
+		response.LastSeq = r.Events.LastSeq
+	}
+	if r.Clock != nil {
+		response.Clock = r.Clock.Copy()
 	}
 
 	// Include game information if game exists
@@ -125,6 +363,13 @@ func (r Room) GetRoomResponse() RoomResponse {
 			Type:   r.Game.GetType(),
 			Status: r.Game.GetStatus(),
 			State:  r.Game.GetState(),
+			Seats:  r.Game.GetSeats(),
+		}
+		// clients highlight the valid moves without knowing the game's rules
+		if game, ok := r.Game.(TurnBasedGame); ok {
+			if lister, ok := r.Game.(MoveLister); ok {
+				response.Game.LegalMoves = lister.LegalMoves(game.CurrentPlayerID())
+			}
 		}
 	}
 
@@ -3,4 +3,12 @@ package model
 type User struct {
 	ID   string `json:"id" binding:"required"`
 	Name string `json:"name" binding:"required"`
+	// IsBot marks the users the server plays for
+	IsBot bool `json:"is_bot,omitempty"`
+}
+
+// UserProfile is a user with their rating in every game they have played
+type UserProfile struct {
+	User
+	Ratings []Rating `json:"ratings"`
 }
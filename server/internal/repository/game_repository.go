@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"sync"
 
-	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
+	"github.com/kaviraj-j/duoplay/internal/games"
 	"github.com/kaviraj-j/duoplay/internal/model"
 )
 
@@ -16,10 +16,10 @@ type GameRepository interface {
 	UpdateGame(ctx context.Context, gameID string, game model.Game) error
 }
 
+// inMemoryGameRepository keeps active games in memory, the available games come from the games registry
 type inMemoryGameRepository struct {
-	availableGames map[model.GameType]model.GameListPayload
-	activeGames    map[string]model.Game
-	mu             sync.RWMutex
+	activeGames map[string]model.Game
+	mu          sync.RWMutex
 }
 
 var (
@@ -28,26 +28,21 @@ var (
 
 func NewGameRepository() GameRepository {
 	gameRepo := &inMemoryGameRepository{
-		availableGames: map[model.GameType]model.GameListPayload{
-			model.TicTacToeGame: {
-				Name:        "tictactoe",
-				DisplayName: "Tic Tac Toe",
-			},
-		},
 		activeGames: make(map[string]model.Game),
 	}
 	return gameRepo
 }
 
 func (gameRepository *inMemoryGameRepository) GetGamesList(ctx context.Context) ([]model.GameListPayload, error) {
-	gameRepository.mu.RLock()
-	defer gameRepository.mu.RUnlock()
-
-	gameTypes := make([]model.GameListPayload, 0, len(gameRepository.availableGames))
-	for gameType := range gameRepository.availableGames {
+	registeredGames := games.List()
+	gameTypes := make([]model.GameListPayload, 0, len(registeredGames))
+	for _, info := range registeredGames {
 		gameTypes = append(gameTypes, model.GameListPayload{
-			Name:        string(gameType),
-			DisplayName: gameRepository.availableGames[gameType].DisplayName,
+			Name:        string(info.Type),
+			DisplayName: info.DisplayName,
+			Description: info.Description,
+			MinPlayers:  info.MinPlayers,
+			MaxPlayers:  info.MaxPlayers,
 		})
 	}
 	return gameTypes, nil
@@ -68,16 +63,8 @@ func (gameRepository *inMemoryGameRepository) CreateGame(ctx context.Context, ga
 	gameRepository.mu.Lock()
 	defer gameRepository.mu.Unlock()
 
-	// Check if the game type is available
-	if _, exists := gameRepository.availableGames[gameType]; !exists {
-		return nil, fmt.Errorf("unsupported game type: %s", gameType)
-	}
-
-	var game model.Game
-	switch gameType {
-	case model.TicTacToeGame:
-		game = tictactoe.NewTicTacToe()
-	default:
+	game, err := games.CreateGameFromName(string(gameType))
+	if err != nil {
 		return nil, fmt.Errorf("unsupported game type: %s", gameType)
 	}
 
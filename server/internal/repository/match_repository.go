@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+var (
+	ErrMatchNotFound = errors.New("match not found")
+)
+
+// MatchRepository stores the finished matches
+type MatchRepository interface {
+	Save(ctx context.Context, match model.Match) error
+	FindByID(ctx context.Context, id string) (model.Match, error)
+	// ListByUser returns a page of the user's matches, newest first, along with how many matches there are in all.
+	// An empty gameType lists the matches of every game
+	ListByUser(ctx context.Context, userID string, gameType model.GameType, limit, offset int) ([]model.Match, int, error)
+}
+
+// inMemoryMatchRepository implements MatchRepository and keeps the matches within the app memory
+type inMemoryMatchRepository struct {
+	matches map[string]model.Match
+	mu      sync.RWMutex
+}
+
+func NewMatchRepository() MatchRepository {
+	return &inMemoryMatchRepository{
+		matches: make(map[string]model.Match),
+	}
+}
+
+func (repository *inMemoryMatchRepository) Save(ctx context.Context, match model.Match) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	repository.matches[match.ID] = match
+	return nil
+}
+
+func (repository *inMemoryMatchRepository) FindByID(ctx context.Context, id string) (model.Match, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+	match, ok := repository.matches[id]
+	if !ok {
+		return model.Match{}, ErrMatchNotFound
+	}
+	return match, nil
+}
+
+func (repository *inMemoryMatchRepository) ListByUser(ctx context.Context, userID string, gameType model.GameType, limit, offset int) ([]model.Match, int, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+	matches := make([]model.Match, 0)
+	for _, match := range repository.matches {
+		if gameType != "" && match.GameType != gameType {
+			continue
+		}
+		for _, player := range match.Players {
+			if player.UserID == userID {
+				matches = append(matches, match)
+				break
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].EndedAt.After(matches[j].EndedAt)
+	})
+
+	total := len(matches)
+	if offset >= total {
+		return []model.Match{}, total, nil
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	return matches[offset:end], total, nil
+}
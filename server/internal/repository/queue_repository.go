@@ -3,37 +3,67 @@ package repository
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
-	"github.com/gorilla/websocket"
 	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/ws"
+)
+
+var (
+	ErrPlayerAlreadyQueued error = fmt.Errorf("player is already in queue")
+	ErrNotEnoughPlayers    error = fmt.Errorf("not enough players in queue")
+	ErrPlayerNotQueued     error = fmt.Errorf("player is not in queue")
 )
 
 type QueueRepository interface {
-	AddToQueue(ctx context.Context, userID string, conn *websocket.Conn) error
+	AddToQueue(ctx context.Context, entry model.QueueEntry) error
 	RemoveFromQueue(ctx context.Context, userID string) error
-	GetWaitingPlayers(ctx context.Context) ([]string, error)
-	GetPlayerConnection(ctx context.Context, userID string) (*websocket.Conn, error)
+	// DequeuePair takes the pair picked by choose off the queues in one step, choose sees every queue
+	// in join order. The players' connections stay open for the room they are matched into
+	DequeuePair(ctx context.Context, choose PairChooser) (model.QueuePair, error)
+	// TakeFromQueue removes a single player from the queue and returns their entry, their connection stays open
+	TakeFromQueue(ctx context.Context, userID string) (model.QueueEntry, error)
+	// GetWaitingPlayers returns every queued player in join order, the player who has waited longest comes first
+	GetWaitingPlayers(ctx context.Context) ([]model.QueueEntry, error)
+	GetPlayerConnection(ctx context.Context, userID string) (*ws.Conn, error)
 	PlayerExistsInQueue(ctx context.Context, userID string) bool
+	// SendToQueued sends msg on the player's queue connection while they are still queued and returns
+	// ErrPlayerNotQueued otherwise, so a player matched in the meantime never gets a stale queue message
+	SendToQueued(ctx context.Context, userID string, msg any) error
 }
 
+// PairChooser picks the players to match from the queues, ok is false when no pair should be made yet
+type PairChooser func(queues map[model.GameType][]model.QueueEntry) (pair model.QueuePair, ok bool)
+
 type inMemoryQueueRepository struct {
-	waitingPlayers map[string]*websocket.Conn
-	mu             sync.RWMutex
-	matchCallback  func(ctx context.Context, player1ID, player2ID string) (*model.Room, error)
+	// queues holds a queue in join order for every game type, players open to any game
+	// wait in the model.AnyGame queue
+	queues map[model.GameType][]model.QueueEntry
+	mu     sync.RWMutex
 }
 
 func NewQueueRepository() QueueRepository {
 	return &inMemoryQueueRepository{
-		waitingPlayers: make(map[string]*websocket.Conn),
+		queues: make(map[model.GameType][]model.QueueEntry),
 	}
 }
 
-func (q *inMemoryQueueRepository) AddToQueue(ctx context.Context, userID string, conn *websocket.Conn) error {
+func (q *inMemoryQueueRepository) AddToQueue(ctx context.Context, entry model.QueueEntry) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	q.waitingPlayers[userID] = conn
+	if _, i := q.indexOf(entry.UserID); i >= 0 {
+		return ErrPlayerAlreadyQueued
+	}
+	if entry.EnqueuedAt.IsZero() {
+		entry.EnqueuedAt = time.Now()
+	}
+	if entry.GameType == "" {
+		entry.GameType = model.AnyGame
+	}
+	q.queues[entry.GameType] = append(q.queues[entry.GameType], entry)
 
 	return nil
 }
@@ -42,42 +72,134 @@ func (q *inMemoryQueueRepository) RemoveFromQueue(ctx context.Context, userID st
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	if conn, exists := q.waitingPlayers[userID]; exists {
-		conn.Close()
-		delete(q.waitingPlayers, userID)
+	if gameType, i := q.indexOf(userID); i >= 0 {
+		q.remove(gameType, i)
 	}
 
 	return nil
 }
 
-func (q *inMemoryQueueRepository) GetWaitingPlayers(ctx context.Context) ([]string, error) {
+func (q *inMemoryQueueRepository) DequeuePair(ctx context.Context, choose PairChooser) (model.QueuePair, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// players whose connection already closed are dropped instead of matched
+	for gameType, queue := range q.queues {
+		for i := 0; i < len(queue); {
+			if queue[i].Conn.IsClosed() {
+				q.remove(gameType, i)
+				queue = q.queues[gameType]
+				continue
+			}
+			i++
+		}
+	}
+
+	// the chooser gets copies so it cannot change the queues behind the lock's back
+	queues := make(map[model.GameType][]model.QueueEntry, len(q.queues))
+	for gameType, queue := range q.queues {
+		queues[gameType] = append([]model.QueueEntry(nil), queue...)
+	}
+	pair, ok := choose(queues)
+	if !ok {
+		return model.QueuePair{}, ErrNotEnoughPlayers
+	}
+
+	for _, entry := range []model.QueueEntry{pair.First, pair.Second} {
+		if _, i := q.indexOf(entry.UserID); i < 0 {
+			return model.QueuePair{}, fmt.Errorf("player %s chosen for a match is not queued", entry.UserID)
+		}
+	}
+	for _, entry := range []model.QueueEntry{pair.First, pair.Second} {
+		gameType, i := q.indexOf(entry.UserID)
+		queue := q.queues[gameType]
+		q.queues[gameType] = append(queue[:i], queue[i+1:]...)
+	}
+
+	return pair, nil
+}
+
+func (q *inMemoryQueueRepository) TakeFromQueue(ctx context.Context, userID string) (model.QueueEntry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	gameType, i := q.indexOf(userID)
+	if i < 0 {
+		return model.QueueEntry{}, ErrPlayerNotQueued
+	}
+	queue := q.queues[gameType]
+	entry := queue[i]
+	q.queues[gameType] = append(queue[:i], queue[i+1:]...)
+	return entry, nil
+}
+
+func (q *inMemoryQueueRepository) GetWaitingPlayers(ctx context.Context) ([]model.QueueEntry, error) {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
 
-	playerIds := make([]string, 0, len(q.waitingPlayers))
-	for userID := range q.waitingPlayers {
-		playerIds = append(playerIds, userID)
+	entries := make([]model.QueueEntry, 0)
+	for _, queue := range q.queues {
+		entries = append(entries, queue...)
 	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].EnqueuedAt.Before(entries[j].EnqueuedAt)
+	})
 
-	return playerIds, nil
+	return entries, nil
 }
 
-func (q *inMemoryQueueRepository) GetPlayerConnection(ctx context.Context, userID string) (*websocket.Conn, error) {
+func (q *inMemoryQueueRepository) GetPlayerConnection(ctx context.Context, userID string) (*ws.Conn, error) {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
 
-	conn, exists := q.waitingPlayers[userID]
-	if !exists {
+	gameType, i := q.indexOf(userID)
+	if i < 0 {
 		return nil, fmt.Errorf("player not found in queue")
 	}
 
-	return conn, nil
+	return q.queues[gameType][i].Conn, nil
 }
 
 func (q *inMemoryQueueRepository) PlayerExistsInQueue(ctx context.Context, userID string) bool {
 	q.mu.RLock()
 	defer q.mu.RUnlock()
 
-	_, exists := q.waitingPlayers[userID]
-	return exists
+	_, i := q.indexOf(userID)
+	return i >= 0
+}
+
+func (q *inMemoryQueueRepository) SendToQueued(ctx context.Context, userID string, msg any) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	gameType, i := q.indexOf(userID)
+	if i < 0 {
+		return ErrPlayerNotQueued
+	}
+	// WriteJSON only queues the message, so holding the lock never waits on the client
+	return q.queues[gameType][i].Conn.WriteJSON(msg)
+}
+
+// remove drops the player at i of the game's queue and closes their connection, the caller holds the lock
+func (q *inMemoryQueueRepository) remove(gameType model.GameType, i int) {
+	queue := q.queues[gameType]
+	entry := queue[i]
+	entry.Conn.Close()
+	if entry.Matched != nil {
+		close(entry.Matched)
+	}
+	// the players behind keep their order
+	q.queues[gameType] = append(queue[:i], queue[i+1:]...)
+}
+
+// indexOf returns the queue the player waits in and their place in it, or -1, the caller holds the lock
+func (q *inMemoryQueueRepository) indexOf(userID string) (model.GameType, int) {
+	for gameType, queue := range q.queues {
+		for i, entry := range queue {
+			if entry.UserID == userID {
+				return gameType, i
+			}
+		}
+	}
+	return "", -1
 }
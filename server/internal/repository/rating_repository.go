@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// RatingRepository stores the players' ratings per game
+type RatingRepository interface {
+	// GetRating returns the player's rating in the game, a player who has not played it gets the default rating
+	GetRating(ctx context.Context, userID string, gameType model.GameType) (model.Rating, error)
+	GetUserRatings(ctx context.Context, userID string) ([]model.Rating, error)
+	// SaveRatings stores all the ratings in one step, so both players of a game are updated together
+	SaveRatings(ctx context.Context, ratings ...model.Rating) error
+}
+
+type ratingKey struct {
+	userID   string
+	gameType model.GameType
+}
+
+// inMemoryRatingRepository implements RatingRepository and keeps the ratings within the app memory
+type inMemoryRatingRepository struct {
+	ratings map[ratingKey]model.Rating
+	mu      sync.RWMutex
+}
+
+func NewRatingRepository() RatingRepository {
+	return &inMemoryRatingRepository{
+		ratings: make(map[ratingKey]model.Rating),
+	}
+}
+
+func (repository *inMemoryRatingRepository) GetRating(ctx context.Context, userID string, gameType model.GameType) (model.Rating, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+	rating, ok := repository.ratings[ratingKey{userID, gameType}]
+	if !ok {
+		return model.NewRating(userID, gameType), nil
+	}
+	return rating, nil
+}
+
+func (repository *inMemoryRatingRepository) GetUserRatings(ctx context.Context, userID string) ([]model.Rating, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+	ratings := make([]model.Rating, 0)
+	for key, rating := range repository.ratings {
+		if key.userID == userID {
+			ratings = append(ratings, rating)
+		}
+	}
+	sort.Slice(ratings, func(i, j int) bool {
+		return ratings[i].GameType < ratings[j].GameType
+	})
+	return ratings, nil
+}
+
+func (repository *inMemoryRatingRepository) SaveRatings(ctx context.Context, ratings ...model.Rating) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	for _, rating := range ratings {
+		repository.ratings[ratingKey{rating.UserID, rating.GameType}] = rating
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+var (
+	ErrReportNotFound = errors.New("report not found")
+)
+
+// ReportRepository stores the reports players make about each other
+type ReportRepository interface {
+	Create(ctx context.Context, report model.Report) error
+	FindByID(ctx context.Context, id string) (model.Report, error)
+	// List returns the reports with the status, or every report when status is empty, oldest first
+	List(ctx context.Context, status model.ReportStatus) ([]model.Report, error)
+	Update(ctx context.Context, report model.Report) error
+}
+
+// inMemoryReportRepository implements ReportRepository and keeps the reports within the app memory
+type inMemoryReportRepository struct {
+	reports map[string]model.Report
+	mu      sync.RWMutex
+}
+
+func NewReportRepository() ReportRepository {
+	return &inMemoryReportRepository{
+		reports: make(map[string]model.Report),
+	}
+}
+
+func (repository *inMemoryReportRepository) Create(ctx context.Context, report model.Report) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	repository.reports[report.ID] = report
+	return nil
+}
+
+func (repository *inMemoryReportRepository) FindByID(ctx context.Context, id string) (model.Report, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+	report, ok := repository.reports[id]
+	if !ok {
+		return model.Report{}, ErrReportNotFound
+	}
+	return report, nil
+}
+
+func (repository *inMemoryReportRepository) List(ctx context.Context, status model.ReportStatus) ([]model.Report, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+	reports := make([]model.Report, 0)
+	for _, report := range repository.reports {
+		if status == "" || report.Status == status {
+			reports = append(reports, report)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].CreatedAt.Before(reports[j].CreatedAt)
+	})
+	return reports, nil
+}
+
+func (repository *inMemoryReportRepository) Update(ctx context.Context, report model.Report) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	if _, ok := repository.reports[report.ID]; !ok {
+		return ErrReportNotFound
+	}
+	repository.reports[report.ID] = report
+	return nil
+}
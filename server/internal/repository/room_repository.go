@@ -13,6 +13,9 @@ type RoomRepository interface {
 	CreateRoom(ctx context.Context, room model.Room) error
 	GetRoomByID(ctx context.Context, id string) (*model.Room, error)
 	AddPlayerToRoom(ctx context.Context, roomID string, player model.Player) error
+	// AddSpectatorToRoom adds a spectator, or swaps in the new connection of one who is already watching
+	AddSpectatorToRoom(ctx context.Context, roomID string, spectator model.Spectator) error
+	RemoveSpectatorFromRoom(ctx context.Context, roomID string, userID string) error
 	DeleteRoom(ctx context.Context, roomID string) error
 	UpdateRoom(ctx context.Context, room model.Room) error
 
@@ -70,8 +73,36 @@ func (roomRepository *inMemoryRoomRepository) AddPlayerToRoom(ctx context.Contex
 		return errors.New("player limit exceeded to join room")
 	}
 	room.Players[player.User.ID] = player
+	// a spectator who takes a free seat stops watching
+	delete(room.Spectators, player.User.ID)
 	return nil
 }
+func (roomRepository *inMemoryRoomRepository) AddSpectatorToRoom(ctx context.Context, roomID string, spectator model.Spectator) error {
+	roomRepository.mu.Lock()
+	defer roomRepository.mu.Unlock()
+	room, ok := roomRepository.rooms[roomID]
+	if !ok {
+		return ErrRoomNotFound
+	}
+	// players have a seat and cannot watch their own room
+	if _, exists := room.Players[spectator.User.ID]; exists {
+		return errors.New("players cannot watch their own room")
+	}
+	room.Spectators[spectator.User.ID] = spectator
+	return nil
+}
+
+func (roomRepository *inMemoryRoomRepository) RemoveSpectatorFromRoom(ctx context.Context, roomID string, userID string) error {
+	roomRepository.mu.Lock()
+	defer roomRepository.mu.Unlock()
+	room, ok := roomRepository.rooms[roomID]
+	if !ok {
+		return ErrRoomNotFound
+	}
+	delete(room.Spectators, userID)
+	return nil
+}
+
 func (roomRepository *inMemoryRoomRepository) DeleteRoom(ctx context.Context, roomID string) error {
 	roomRepository.mu.Lock()
 	defer roomRepository.mu.Unlock()
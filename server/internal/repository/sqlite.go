@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// migrations are the schema changes of the SQLite database in the order they are applied,
+// a released migration must never change, new schema changes are appended
+var migrations = []string{
+	// 1: users
+	`CREATE TABLE users (
+		id         TEXT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		is_bot     INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`,
+	// 2: match history
+	`CREATE TABLE matches (
+		id         TEXT PRIMARY KEY,
+		room_id    TEXT NOT NULL,
+		game_type  TEXT NOT NULL,
+		winner_id  TEXT NOT NULL DEFAULT '',
+		end_reason TEXT NOT NULL,
+		started_at TIMESTAMP NOT NULL,
+		ended_at   TIMESTAMP NOT NULL
+	);
+	CREATE TABLE match_players (
+		match_id TEXT NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+		user_id  TEXT NOT NULL,
+		name     TEXT NOT NULL,
+		seat     INTEGER NOT NULL,
+		mark     TEXT NOT NULL DEFAULT '',
+		is_bot   INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (match_id, user_id)
+	);
+	CREATE INDEX match_players_user ON match_players(user_id);
+	CREATE TABLE match_moves (
+		match_id  TEXT NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+		seq       INTEGER NOT NULL,
+		player_id TEXT NOT NULL,
+		move      TEXT NOT NULL,
+		played_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (match_id, seq)
+	)`,
+	// 3: ratings
+	`CREATE TABLE ratings (
+		user_id    TEXT NOT NULL,
+		game_type  TEXT NOT NULL,
+		rating     INTEGER NOT NULL,
+		games      INTEGER NOT NULL DEFAULT 0,
+		wins       INTEGER NOT NULL DEFAULT 0,
+		losses     INTEGER NOT NULL DEFAULT 0,
+		draws      INTEGER NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (user_id, game_type)
+	)`,
+}
+
+// OpenSQLite opens the SQLite database at path, creating it when it does not exist,
+// and brings its schema up to date
+func OpenSQLite(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite takes one writer at a time, a single connection keeps writers from failing on a locked database
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(context.Background(), db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// migrate applies the migrations the database has not seen yet, each in its own transaction
+func migrate(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+
+	var version int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this server knows (%d)", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("applying migration %d: %w", i+1, err)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, i+1); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("recording migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("committing migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// sqliteMatchRepository implements MatchRepository on a SQLite database
+type sqliteMatchRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteMatchRepository creates a match repository on a database opened with OpenSQLite
+func NewSQLiteMatchRepository(db *sql.DB) MatchRepository {
+	return &sqliteMatchRepository{db: db}
+}
+
+// Save stores the match with its players and moves in one transaction
+func (repository *sqliteMatchRepository) Save(ctx context.Context, match model.Match) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// times are stored in UTC so they sort in the order they happened
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO matches (id, room_id, game_type, winner_id, end_reason, started_at, ended_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		match.ID, match.RoomID, match.GameType, match.WinnerID, match.EndReason, match.StartedAt.UTC(), match.EndedAt.UTC()); err != nil {
+		return err
+	}
+	for _, player := range match.Players {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO match_players (match_id, user_id, name, seat, mark, is_bot) VALUES (?, ?, ?, ?, ?, ?)`,
+			match.ID, player.UserID, player.Name, player.Seat, player.Mark, player.IsBot); err != nil {
+			return err
+		}
+	}
+	for seq, move := range match.Moves {
+		encoded, err := json.Marshal(move.Move)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO match_moves (match_id, seq, player_id, move, played_at) VALUES (?, ?, ?, ?, ?)`,
+			match.ID, seq, move.PlayerID, string(encoded), move.PlayedAt.UTC()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (repository *sqliteMatchRepository) FindByID(ctx context.Context, id string) (model.Match, error) {
+	var match model.Match
+	err := repository.db.QueryRowContext(ctx,
+		`SELECT id, room_id, game_type, winner_id, end_reason, started_at, ended_at FROM matches WHERE id = ?`, id).
+		Scan(&match.ID, &match.RoomID, &match.GameType, &match.WinnerID, &match.EndReason, &match.StartedAt, &match.EndedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Match{}, ErrMatchNotFound
+	}
+	if err != nil {
+		return model.Match{}, err
+	}
+	if err := repository.loadDetails(ctx, &match); err != nil {
+		return model.Match{}, err
+	}
+	return match, nil
+}
+
+func (repository *sqliteMatchRepository) ListByUser(ctx context.Context, userID string, gameType model.GameType, limit, offset int) ([]model.Match, int, error) {
+	// an empty game type matches every game
+	var total int
+	if err := repository.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM matches m JOIN match_players p ON p.match_id = m.id
+		WHERE p.user_id = ? AND (? = '' OR m.game_type = ?)`,
+		userID, gameType, gameType).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// a negative LIMIT is no limit in SQLite
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := repository.db.QueryContext(ctx,
+		`SELECT m.id, m.room_id, m.game_type, m.winner_id, m.end_reason, m.started_at, m.ended_at
+		FROM matches m JOIN match_players p ON p.match_id = m.id
+		WHERE p.user_id = ? AND (? = '' OR m.game_type = ?)
+		ORDER BY m.ended_at DESC, m.id LIMIT ? OFFSET ?`,
+		userID, gameType, gameType, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	matches := make([]model.Match, 0)
+	for rows.Next() {
+		var match model.Match
+		if err := rows.Scan(&match.ID, &match.RoomID, &match.GameType, &match.WinnerID, &match.EndReason, &match.StartedAt, &match.EndedAt); err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		matches = append(matches, match)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// the rows are closed first, the database has a single connection
+	for i := range matches {
+		if err := repository.loadDetails(ctx, &matches[i]); err != nil {
+			return nil, 0, err
+		}
+	}
+	return matches, total, nil
+}
+
+// loadDetails fills in the players and moves of the match
+func (repository *sqliteMatchRepository) loadDetails(ctx context.Context, match *model.Match) error {
+	playerRows, err := repository.db.QueryContext(ctx,
+		`SELECT user_id, name, seat, mark, is_bot FROM match_players WHERE match_id = ? ORDER BY seat`, match.ID)
+	if err != nil {
+		return err
+	}
+	defer playerRows.Close()
+	match.Players = make([]model.MatchPlayer, 0, 2)
+	for playerRows.Next() {
+		var player model.MatchPlayer
+		if err := playerRows.Scan(&player.UserID, &player.Name, &player.Seat, &player.Mark, &player.IsBot); err != nil {
+			return err
+		}
+		match.Players = append(match.Players, player)
+	}
+	if err := playerRows.Err(); err != nil {
+		return err
+	}
+	playerRows.Close()
+
+	moveRows, err := repository.db.QueryContext(ctx,
+		`SELECT player_id, move, played_at FROM match_moves WHERE match_id = ? ORDER BY seq`, match.ID)
+	if err != nil {
+		return err
+	}
+	defer moveRows.Close()
+	match.Moves = make([]model.MatchMove, 0)
+	for moveRows.Next() {
+		var move model.MatchMove
+		var encoded string
+		if err := moveRows.Scan(&move.PlayerID, &encoded, &move.PlayedAt); err != nil {
+			return err
+		}
+		// moves are kept as the JSON they were stored as
+		move.Move = json.RawMessage(encoded)
+		match.Moves = append(match.Moves, move)
+	}
+	return moveRows.Err()
+}
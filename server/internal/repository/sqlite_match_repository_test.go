@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+type testMove struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+// testMatches returns matches between alice and bob or carol, alternating games, ending one minute apart
+func testMatches() []model.Match {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	matches := make([]model.Match, 0, 7)
+	for i := 0; i < 7; i++ {
+		opponent := "bob"
+		if i%3 == 2 {
+			opponent = "carol"
+		}
+		gameType := model.GameType("tictactoe")
+		if i%2 == 1 {
+			gameType = "connectfour"
+		}
+		startedAt := start.Add(time.Duration(i) * time.Minute)
+		matches = append(matches, model.Match{
+			ID:       fmt.Sprintf("match-%d", i),
+			RoomID:   fmt.Sprintf("room-%d", i),
+			GameType: gameType,
+			Players: []model.MatchPlayer{
+				{UserID: "alice", Name: "Alice", Seat: 0, Mark: "X"},
+				{UserID: opponent, Name: opponent, Seat: 1, Mark: "O", IsBot: opponent == "carol"},
+			},
+			Moves: []model.MatchMove{
+				{PlayerID: "alice", Move: testMove{Row: 0, Col: i % 3}, PlayedAt: startedAt.Add(time.Second)},
+				{PlayerID: opponent, Move: testMove{Row: 1, Col: 1}, PlayedAt: startedAt.Add(2 * time.Second)},
+			},
+			WinnerID:  "alice",
+			EndReason: model.EndReasonCompleted,
+			StartedAt: startedAt,
+			EndedAt:   startedAt.Add(30 * time.Second),
+		})
+	}
+	return matches
+}
+
+func matchIDs(matches []model.Match) []string {
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.ID)
+	}
+	return ids
+}
+
+// normalizeMoves encodes every move as JSON, the way the SQLite repository returns them
+func normalizeMoves(t *testing.T, match model.Match) model.Match {
+	t.Helper()
+	moves := make([]model.MatchMove, 0, len(match.Moves))
+	for _, move := range match.Moves {
+		encoded, err := json.Marshal(move.Move)
+		if err != nil {
+			t.Fatalf("encoding move: %v", err)
+		}
+		move.Move = json.RawMessage(encoded)
+		moves = append(moves, move)
+	}
+	match.Moves = moves
+	return match
+}
+
+func TestSQLiteMatchRepositoryMatchesInMemory(t *testing.T) {
+	ctx := context.Background()
+	sqlite := NewSQLiteMatchRepository(openTestSQLite(t))
+	memory := NewMatchRepository()
+	for _, match := range testMatches() {
+		for _, repo := range []MatchRepository{sqlite, memory} {
+			if err := repo.Save(ctx, match); err != nil {
+				t.Fatalf("Save %s: %v", match.ID, err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		userID   string
+		gameType model.GameType
+		limit    int
+		offset   int
+	}{
+		{"first page", "alice", "", 3, 0},
+		{"second page", "alice", "", 3, 3},
+		{"last partial page", "alice", "", 3, 6},
+		{"past the end", "alice", "", 3, 9},
+		{"no limit", "alice", "", 0, 0},
+		{"game filter", "alice", "connectfour", 10, 0},
+		{"game filter with offset", "alice", "tictactoe", 2, 1},
+		{"opponent", "carol", "", 10, 0},
+		{"unknown user", "dave", "", 10, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, wantTotal, err := memory.ListByUser(ctx, tt.userID, tt.gameType, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("in-memory ListByUser: %v", err)
+			}
+			got, total, err := sqlite.ListByUser(ctx, tt.userID, tt.gameType, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("sqlite ListByUser: %v", err)
+			}
+			if total != wantTotal {
+				t.Errorf("total = %d, want %d", total, wantTotal)
+			}
+			if !reflect.DeepEqual(matchIDs(got), matchIDs(want)) {
+				t.Fatalf("matches = %v, want %v", matchIDs(got), matchIDs(want))
+			}
+			for i := range got {
+				if !reflect.DeepEqual(got[i], normalizeMoves(t, want[i])) {
+					t.Errorf("match %s = %+v, want %+v", got[i].ID, got[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSQLiteMatchRepositoryFindByID(t *testing.T) {
+	ctx := context.Background()
+	repo := NewSQLiteMatchRepository(openTestSQLite(t))
+	match := testMatches()[1]
+	if err := repo.Save(ctx, match); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, match.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if !reflect.DeepEqual(got, normalizeMoves(t, match)) {
+		t.Errorf("FindByID = %+v, want %+v", got, match)
+	}
+	if _, err := repo.FindByID(ctx, "missing"); !errors.Is(err, ErrMatchNotFound) {
+		t.Errorf("FindByID of a missing match returned %v, want %v", err, ErrMatchNotFound)
+	}
+	// a match is saved once, a second save must not duplicate its players or moves
+	if err := repo.Save(ctx, match); err == nil {
+		t.Error("saving the same match twice succeeded")
+	}
+}
+
+func TestSQLiteMatchRepositoryTimeRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	repo := NewSQLiteMatchRepository(openTestSQLite(t))
+	zone := time.FixedZone("UTC+5:30", 5*3600+30*60)
+	startedAt := time.Date(2024, 12, 31, 23, 59, 58, 123456789, zone)
+
+	match := testMatches()[0]
+	match.StartedAt = startedAt
+	match.EndedAt = startedAt.Add(1500 * time.Millisecond)
+	match.Moves[0].PlayedAt = startedAt.Add(time.Second)
+	if err := repo.Save(ctx, match); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := repo.FindByID(ctx, match.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	times := []struct {
+		name      string
+		got, want time.Time
+	}{
+		{"started_at", got.StartedAt, match.StartedAt},
+		{"ended_at", got.EndedAt, match.EndedAt},
+		{"played_at", got.Moves[0].PlayedAt, match.Moves[0].PlayedAt},
+	}
+	for _, tt := range times {
+		if !tt.got.Equal(tt.want) {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+		if tt.got.Location() != time.UTC {
+			t.Errorf("%s comes back in %v, want UTC", tt.name, tt.got.Location())
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// sqliteRatingRepository implements RatingRepository on a SQLite database, ratings outlive a server restart
+type sqliteRatingRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRatingRepository creates a rating repository on a database opened with OpenSQLite
+func NewSQLiteRatingRepository(db *sql.DB) RatingRepository {
+	return &sqliteRatingRepository{db: db}
+}
+
+func (repository *sqliteRatingRepository) GetRating(ctx context.Context, userID string, gameType model.GameType) (model.Rating, error) {
+	rating := model.Rating{UserID: userID, GameType: gameType}
+	err := repository.db.QueryRowContext(ctx,
+		`SELECT rating, games, wins, losses, draws, updated_at FROM ratings WHERE user_id = ? AND game_type = ?`,
+		userID, gameType).
+		Scan(&rating.Rating, &rating.Games, &rating.Wins, &rating.Losses, &rating.Draws, &rating.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.NewRating(userID, gameType), nil
+	}
+	if err != nil {
+		return model.Rating{}, err
+	}
+	return rating, nil
+}
+
+func (repository *sqliteRatingRepository) GetUserRatings(ctx context.Context, userID string) ([]model.Rating, error) {
+	rows, err := repository.db.QueryContext(ctx,
+		`SELECT game_type, rating, games, wins, losses, draws, updated_at FROM ratings WHERE user_id = ? ORDER BY game_type`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := make([]model.Rating, 0)
+	for rows.Next() {
+		rating := model.Rating{UserID: userID}
+		if err := rows.Scan(&rating.GameType, &rating.Rating, &rating.Games, &rating.Wins, &rating.Losses, &rating.Draws, &rating.UpdatedAt); err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, rating)
+	}
+	return ratings, rows.Err()
+}
+
+// SaveRatings upserts all the ratings in one transaction, so both players of a game are updated together
+func (repository *sqliteRatingRepository) SaveRatings(ctx context.Context, ratings ...model.Rating) error {
+	tx, err := repository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, rating := range ratings {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO ratings (user_id, game_type, rating, games, wins, losses, draws, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (user_id, game_type) DO UPDATE SET
+				rating = excluded.rating, games = excluded.games, wins = excluded.wins,
+				losses = excluded.losses, draws = excluded.draws, updated_at = excluded.updated_at`,
+			rating.UserID, rating.GameType, rating.Rating, rating.Games, rating.Wins, rating.Losses, rating.Draws, rating.UpdatedAt.UTC()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
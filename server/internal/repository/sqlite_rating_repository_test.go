@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+func TestSQLiteRatingRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewSQLiteRatingRepository(openTestSQLite(t))
+
+	// a player who has not played gets the default rating
+	rating, err := repo.GetRating(ctx, "alice", "tictactoe")
+	if err != nil {
+		t.Fatalf("GetRating: %v", err)
+	}
+	if rating != model.NewRating("alice", "tictactoe") {
+		t.Errorf("unrated player got %+v", rating)
+	}
+
+	updatedAt := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	saved := []model.Rating{
+		{UserID: "alice", GameType: "tictactoe", Rating: 1216, Games: 1, Wins: 1, UpdatedAt: updatedAt},
+		{UserID: "bob", GameType: "tictactoe", Rating: 1184, Games: 1, Losses: 1, UpdatedAt: updatedAt},
+		{UserID: "alice", GameType: "connectfour", Rating: 1200, Games: 1, Draws: 1, UpdatedAt: updatedAt},
+	}
+	if err := repo.SaveRatings(ctx, saved...); err != nil {
+		t.Fatalf("SaveRatings: %v", err)
+	}
+	// saving again updates the rows in place
+	saved[0].Rating, saved[0].Games, saved[0].Wins = 1230, 2, 2
+	if err := repo.SaveRatings(ctx, saved[0]); err != nil {
+		t.Fatalf("SaveRatings update: %v", err)
+	}
+
+	got, err := repo.GetUserRatings(ctx, "alice")
+	if err != nil {
+		t.Fatalf("GetUserRatings: %v", err)
+	}
+	want := []model.Rating{saved[2], saved[0]}
+	if len(got) != len(want) {
+		t.Fatalf("GetUserRatings returned %d ratings, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].UpdatedAt.Equal(want[i].UpdatedAt) {
+			t.Errorf("rating %d UpdatedAt = %v, want %v", i, got[i].UpdatedAt, want[i].UpdatedAt)
+		}
+		got[i].UpdatedAt = want[i].UpdatedAt
+		if got[i] != want[i] {
+			t.Errorf("rating %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// openTestSQLite opens a database in a temporary file that is removed when the test ends
+func openTestSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "duoplay.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigrationsApplyOnce(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "duoplay.db")
+
+	for open := 1; open <= 2; open++ {
+		db, err := OpenSQLite(path)
+		if err != nil {
+			t.Fatalf("open %d: %v", open, err)
+		}
+		// running migrate again on an up to date database changes nothing
+		if err := migrate(ctx, db); err != nil {
+			t.Fatalf("open %d: migrate again: %v", open, err)
+		}
+		var count, version int
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*), MAX(version) FROM schema_migrations`).Scan(&count, &version); err != nil {
+			t.Fatalf("reading schema_migrations: %v", err)
+		}
+		if count != len(migrations) || version != len(migrations) {
+			t.Errorf("open %d: %d migrations recorded up to version %d, want %d", open, count, version, len(migrations))
+		}
+		db.Close()
+	}
+}
+
+func TestMigrateRejectsNewerSchema(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQLite(t)
+	if _, err := db.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, len(migrations)+1); err != nil {
+		t.Fatalf("recording a future migration: %v", err)
+	}
+	if err := migrate(ctx, db); err == nil {
+		t.Error("migrate accepted a schema newer than the server knows")
+	}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// sqliteUserRepository implements UserRepository on a SQLite database, users outlive a server restart
+type sqliteUserRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteUserRepository creates a user repository on a database opened with OpenSQLite
+func NewSQLiteUserRepository(db *sql.DB) UserRepository {
+	return &sqliteUserRepository{db: db}
+}
+
+func (repository *sqliteUserRepository) Create(ctx context.Context, user *model.User) error {
+	_, err := repository.db.ExecContext(ctx,
+		`INSERT INTO users (id, name, is_bot) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET name = excluded.name, is_bot = excluded.is_bot`,
+		user.ID, user.Name, user.IsBot)
+	return err
+}
+
+func (repository *sqliteUserRepository) FindByID(ctx context.Context, id string) (*model.User, error) {
+	user := &model.User{}
+	err := repository.db.QueryRowContext(ctx,
+		`SELECT id, name, is_bot FROM users WHERE id = ?`, id).
+		Scan(&user.ID, &user.Name, &user.IsBot)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &model.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return &model.User{}, err
+	}
+	return user, nil
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/bots"
+	"github.com/kaviraj-j/duoplay/internal/games"
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+)
+
+var (
+	ErrorInvalidBotDifficulty = errors.New("unknown bot difficulty")
+	ErrorNoBotOffer           = errors.New("no bot has been offered")
+	ErrorHintsNotAllowed      = errors.New("hints are only given in practice rooms")
+)
+
+// botMoveDelay keeps a bot from answering faster than the human can see their own move land
+const botMoveDelay = 500 * time.Millisecond
+
+// CreateBotRoom starts a game between the player and a bot in a new room, the room's start_game
+// message is sent to the player before it returns
+func (s *RoomService) CreateBotRoom(ctx context.Context, human model.Player, gameType model.GameType, difficulty model.BotDifficulty) (model.RoomResponse, error) {
+	return s.createBotRoom(ctx, human, gameType, difficulty, nil)
+}
+
+// createBotRoom seats the player and a bot in a new room and starts the game, matched receives the room ID
+// before anything is sent in the room when the player's connection comes from the queue
+func (s *RoomService) createBotRoom(ctx context.Context, human model.Player, gameType model.GameType, difficulty model.BotDifficulty, matched chan<- string) (model.RoomResponse, error) {
+	if gameType == "" {
+		gameType = model.AnyGame
+	}
+	gameType, supported := botGame(gameType)
+	if !supported {
+		return model.RoomResponse{}, ErrorUnsupportedGame
+	}
+	if difficulty != "" && !difficulty.IsValid() {
+		return model.RoomResponse{}, ErrorInvalidBotDifficulty
+	}
+
+	botPlayer, err := bots.NewPlayer(gameType, difficulty)
+	if err != nil {
+		return model.RoomResponse{}, err
+	}
+	game, err := games.CreateGameFromName(string(gameType))
+	if err != nil {
+		return model.RoomResponse{}, fmt.Errorf("failed to create game: %v", err)
+	}
+
+	// the player hosts the room and the game is already chosen for both seats
+	room := model.NewRoom()
+	room.Players[human.User.ID] = human
+	room.Players[botPlayer.User.ID] = botPlayer
+	room.Seating.HostID = human.User.ID
+	room.GameSelection.PlayerChoices[human.User.ID] = gameType
+	room.GameSelection.PlayerChoices[botPlayer.User.ID] = gameType
+	room.Status = model.RoomStatusGameSelection
+
+	if err := s.registerRoom(ctx, room); err != nil {
+		return model.RoomResponse{}, err
+	}
+
+	var roomResponse model.RoomResponse
+	err = s.withRoom(ctx, room.ID, func(room *model.Room) error {
+		for _, p := range room.Players {
+			s.publish(room, model.RoomEventTypePlayerJoined, p.User.ID, nil)
+		}
+		room.Game = game
+		if err := s.startRound(room); err != nil {
+			return err
+		}
+		roomResponse = room.GetRoomResponse()
+
+		if matched != nil {
+			matched <- room.ID
+		}
+		broadcast(room, map[string]interface{}{
+			"type":      model.MessageTypeStartGame,
+			"game_type": gameType,
+			"room_id":   room.ID,
+			"data":      roomResponse,
+		})
+		return nil
+	})
+	if err != nil {
+		s.removeRoom(ctx, room.ID)
+		return model.RoomResponse{}, err
+	}
+	return roomResponse, nil
+}
+
+// scheduleBotTurn lets the bot move after botMoveDelay when it is a bot's turn in the room
+func (s *RoomService) scheduleBotTurn(room *model.Room) {
+	if _, ok := currentBot(room); !ok {
+		return
+	}
+	roomID := room.ID
+	time.AfterFunc(botMoveDelay, func() {
+		if err := s.playBotTurn(roomID); err != nil && !errors.Is(err, repository.ErrRoomNotFound) {
+			fmt.Printf("Bot failed to move in room %s: %v\n", roomID, err)
+		}
+	})
+}
+
+// playBotTurn makes the bot's move. A bot thinks on a clone of the game outside the room's actor so the
+// room keeps serving its players, its move is dropped if the game changed in the meantime since the
+// change scheduled the bot's next turn. Games that cannot be cloned are played on the spot
+func (s *RoomService) playBotTurn(roomID string) error {
+	var bot model.Player
+	var game model.Game
+	var round int
+	err := s.withRoom(s.ctx, roomID, func(room *model.Room) error {
+		player, ok := currentBot(room)
+		if !ok {
+			return nil
+		}
+		cloner, ok := room.Game.(model.Cloner)
+		if !ok {
+			move, err := player.Bot.ChooseMove(room.Game, player.User.ID)
+			if err != nil {
+				return err
+			}
+			return s.applyMove(room, player.User.ID, move)
+		}
+		bot, game, round = player, cloner.Clone(), room.Seating.Round
+		return nil
+	})
+	if err != nil || game == nil {
+		return err
+	}
+
+	move, err := bot.Bot.ChooseMove(game, bot.User.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.withRoom(s.ctx, roomID, func(room *model.Room) error {
+		current, ok := currentBot(room)
+		if !ok || current.User.ID != bot.User.ID || room.Seating.Round != round ||
+			!reflect.DeepEqual(room.Game.GetState(), game.GetState()) {
+			return nil
+		}
+		return s.applyMove(room, bot.User.ID, move)
+	})
+}
+
+// currentBot returns the bot player whose turn it is in the room's running game
+func currentBot(room *model.Room) (model.Player, bool) {
+	if room.Status != model.RoomStatusGameStarted {
+		return model.Player{}, false
+	}
+	game, ok := room.Game.(model.TurnBasedGame)
+	if !ok {
+		return model.Player{}, false
+	}
+	player, exists := room.Players[game.CurrentPlayerID()]
+	if !exists || player.Bot == nil {
+		return model.Player{}, false
+	}
+	return player, true
+}
+
+// botGame returns the game a bot can play with a player queued for gameType
+func botGame(gameType model.GameType) (model.GameType, bool) {
+	if gameType != model.AnyGame {
+		return gameType, bots.Supports(gameType)
+	}
+	if supported := bots.Games(); len(supported) > 0 {
+		return supported[0], true
+	}
+	return "", false
+}
+
+// offerBots offers a game against a bot to the players who have waited in the queue for longer than
+// RoomConfig.BotOfferAfter, every player is offered a bot once per queue visit
+func (s *RoomService) offerBots(ctx context.Context) {
+	if s.config.BotOfferAfter < 0 {
+		return
+	}
+	entries, err := s.queueRepo.GetWaitingPlayers(ctx)
+	if err != nil {
+		return
+	}
+
+	s.botOffersMu.Lock()
+	defer s.botOffersMu.Unlock()
+
+	now := time.Now()
+	waiting := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		waiting[entry.UserID] = true
+		if s.botOffers[entry.UserID] || now.Sub(entry.EnqueuedAt) < s.config.BotOfferAfter {
+			continue
+		}
+		gameType, ok := botGame(entry.GameType)
+		if !ok {
+			continue
+		}
+
+		// a player matched since the snapshot was taken is not offered a bot
+		err := s.queueRepo.SendToQueued(ctx, entry.UserID, map[string]interface{}{
+			"type":    model.MessageTypeBotOffer,
+			"message": "No opponent found yet, you can play against a bot instead.",
+			"data": map[string]interface{}{
+				"game_type":    gameType,
+				"difficulties": []model.BotDifficulty{model.BotDifficultyRandom, model.BotDifficultyGreedy, model.BotDifficultyPerfect},
+				"difficulty":   bots.DefaultDifficulty,
+			},
+		})
+		if err == nil {
+			s.botOffers[entry.UserID] = true
+		}
+	}
+
+	// offers to players who have left the queue are forgotten
+	for userID := range s.botOffers {
+		if !waiting[userID] {
+			delete(s.botOffers, userID)
+		}
+	}
+}
+
+// AcceptBotOffer takes the player off the queue into a room with a bot, their queue connection carries on
+// as the connection to the room and the room ID is sent on their matched channel
+func (s *RoomService) AcceptBotOffer(ctx context.Context, userID string, difficulty model.BotDifficulty) error {
+	if difficulty != "" && !difficulty.IsValid() {
+		return ErrorInvalidBotDifficulty
+	}
+
+	s.botOffersMu.Lock()
+	offered := s.botOffers[userID]
+	delete(s.botOffers, userID)
+	s.botOffersMu.Unlock()
+	if !offered {
+		return ErrorNoBotOffer
+	}
+
+	// the player may have been matched with a person in the meantime
+	entry, err := s.queueRepo.TakeFromQueue(ctx, userID)
+	if err != nil {
+		return ErrorNoBotOffer
+	}
+	gameType, _ := botGame(entry.GameType)
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err == nil {
+		human := model.Player{User: *user, Conn: entry.Conn}
+		_, err = s.createBotRoom(ctx, human, gameType, difficulty, entry.Matched)
+	}
+	if err != nil {
+		entry.Conn.WriteJSON(map[string]interface{}{
+			"type":    model.MessageTypeError,
+			"message": "Failed to create match",
+		})
+		entry.Conn.Close()
+		close(entry.Matched)
+		return err
+	}
+
+	// everyone behind the player moves up
+	s.NotifyQueuePositions(ctx)
+	return nil
+}
+
+// RequestHint returns the move a perfect bot would make for the player, like a bot it thinks on a clone of
+// the game when the game can be cloned. Hints are only given in practice rooms and on the player's turn
+func (s *RoomService) RequestHint(ctx context.Context, roomID string, player model.Player) (any, error) {
+	var bot model.Bot
+	var game model.Game
+	var move any
+	err := s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, player); err != nil {
+			return err
+		}
+		if !room.IsPractice() {
+			return ErrorHintsNotAllowed
+		}
+		if room.Status != model.RoomStatusGameStarted {
+			return errors.New("game is not in progress")
+		}
+		turnBased, ok := room.Game.(model.TurnBasedGame)
+		if !ok || turnBased.CurrentPlayerID() != player.User.ID {
+			return errors.New("not your turn")
+		}
+
+		var err error
+		bot, err = bots.New(room.Game.GetType(), model.BotDifficultyPerfect)
+		if err != nil {
+			return err
+		}
+		if cloner, ok := room.Game.(model.Cloner); ok {
+			game = cloner.Clone()
+			return nil
+		}
+		move, err = bot.ChooseMove(room.Game, player.User.ID)
+		return err
+	})
+	if err != nil || game == nil {
+		return move, err
+	}
+	return bot.ChooseMove(game, player.User.ID)
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// waitForMoves waits until the room's game has at least count moves, the bot moves after botMoveDelay
+func waitForMoves(t *testing.T, s *RoomService, roomID string, count int) []model.MoveRecord {
+	t.Helper()
+	deadline := time.Now().Add(10 * botMoveDelay)
+	for {
+		var history []model.MoveRecord
+		s.withRoom(context.Background(), roomID, func(room *model.Room) error {
+			history = room.Game.(model.Undoer).History()
+			return nil
+		})
+		if len(history) >= count {
+			return history
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the game has %d moves after %s, want %d", len(history), 10*botMoveDelay, count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBotRepliesToAMove(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	alice := testPlayer("alice")
+	room, err := s.CreateBotRoom(ctx, alice, tictactoe.GameType, model.BotDifficultyPerfect)
+	if err != nil {
+		t.Fatalf("CreateBotRoom: %v", err)
+	}
+	if room.Status != model.RoomStatusGameStarted {
+		t.Fatalf("bot room status = %s, want %s", room.Status, model.RoomStatusGameStarted)
+	}
+
+	// the bot opens when it has the first seat
+	played := 0
+	if room.Seating.Order[0] != alice.User.ID {
+		played = len(waitForMoves(t, s, room.ID, 1))
+	}
+
+	var move any
+	s.withRoom(ctx, room.ID, func(room *model.Room) error {
+		move = room.Game.(model.MoveLister).LegalMoves(alice.User.ID)[0]
+		return nil
+	})
+	if err := s.HandleGameMove(ctx, room.ID, alice, move); err != nil {
+		t.Fatalf("HandleGameMove: %v", err)
+	}
+
+	history := waitForMoves(t, s, room.ID, played+2)
+	reply := history[played+1]
+	if player := room.Players[reply.PlayerID]; !player.User.IsBot {
+		t.Errorf("move after alice's was played by %s, want the bot", reply.PlayerID)
+	}
+}
+
+func TestHintsOnlyInPracticeRooms(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	roomID, _, _ := startTicTacToe(t, s)
+	first, _ := seatedPlayers(s, roomID)
+
+	if _, err := s.RequestHint(ctx, roomID, testPlayer(first)); !errors.Is(err, ErrorHintsNotAllowed) {
+		t.Errorf("hint in a rated room returned %v, want %v", err, ErrorHintsNotAllowed)
+	}
+}
+
+func TestHintIsALegalMove(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	alice := testPlayer("alice")
+	room, err := s.CreateBotRoom(ctx, alice, tictactoe.GameType, model.BotDifficultyRandom)
+	if err != nil {
+		t.Fatalf("CreateBotRoom: %v", err)
+	}
+	if room.Seating.Order[0] != alice.User.ID {
+		waitForMoves(t, s, room.ID, 1)
+	}
+
+	hint, err := s.RequestHint(ctx, room.ID, alice)
+	if err != nil {
+		t.Fatalf("RequestHint: %v", err)
+	}
+	var legal []any
+	s.withRoom(ctx, room.ID, func(room *model.Room) error {
+		legal = room.Game.(model.MoveLister).LegalMoves(alice.User.ID)
+		return nil
+	})
+	found := false
+	for _, move := range legal {
+		found = found || move == hint
+	}
+	if !found {
+		t.Errorf("hint %+v is not one of the legal moves %v", hint, legal)
+	}
+	if err := s.HandleGameMove(ctx, room.ID, alice, hint); err != nil {
+		t.Errorf("playing the hint: %v", err)
+	}
+
+	// the bot is to move now, so there is nothing to hint at
+	if _, err := s.RequestHint(ctx, room.ID, alice); err == nil {
+		t.Errorf("hint out of turn returned no error")
+	}
+}
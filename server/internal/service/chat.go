@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/ws"
+)
+
+const (
+	// chatRateLimit is how many chat messages a user may send within chatRateWindow, across all rooms
+	chatRateLimit  = 5
+	chatRateWindow = 10 * time.Second
+)
+
+var (
+	ErrorEmptyChatMessage   = errors.New("chat message is empty")
+	ErrorChatMessageTooLong = fmt.Errorf("chat message is longer than %d characters", model.MaxChatMessageLength)
+	ErrorChatRateLimited    = errors.New("you are sending messages too fast")
+)
+
+// chatLimiter keeps the times of every user's recent chat messages
+type chatLimiter struct {
+	mu   sync.Mutex
+	sent map[string][]time.Time
+	// lastSweep is when users with no message left in the window were last evicted
+	lastSweep time.Time
+}
+
+// allow records a message from the user and reports whether it is within the rate limit
+func (l *chatLimiter) allow(userID string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.sent == nil {
+		l.sent = make(map[string][]time.Time)
+	}
+	// users who stopped chatting are forgotten once a window, so the map only holds recent senders
+	if now.Sub(l.lastSweep) >= chatRateWindow {
+		for id, times := range l.sent {
+			if len(recentMessages(times, now)) == 0 {
+				delete(l.sent, id)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	recent := recentMessages(l.sent[userID], now)
+	if len(recent) >= chatRateLimit {
+		l.sent[userID] = recent
+		return false
+	}
+	l.sent[userID] = append(recent, now)
+	return true
+}
+
+// recentMessages drops the send times that are outside the window, reusing the slice
+func recentMessages(times []time.Time, now time.Time) []time.Time {
+	recent := times[:0]
+	for _, sentAt := range times {
+		if now.Sub(sentAt) < chatRateWindow {
+			recent = append(recent, sentAt)
+		}
+	}
+	return recent
+}
+
+// HandleChatMessage delivers a chat message from a player or spectator of the room to everyone in it,
+// except to a player who muted the sender. Blocked words are masked before the message is kept or sent
+func (s *RoomService) HandleChatMessage(ctx context.Context, roomID string, user model.User, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ErrorEmptyChatMessage
+	}
+	if utf8.RuneCountInString(text) > model.MaxChatMessageLength {
+		return ErrorChatMessageTooLong
+	}
+	text = s.config.ChatFilter.Clean(text)
+
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		_, isPlayer := room.Players[user.ID]
+		_, isSpectator := room.Spectators[user.ID]
+		if !isPlayer && !isSpectator {
+			return ErrorPlayerNotInRoom
+		}
+		now := time.Now()
+		if !s.chatLimiter.allow(user.ID, now) {
+			return ErrorChatRateLimited
+		}
+
+		message := model.ChatMessage{
+			UserID:    user.ID,
+			Name:      user.Name,
+			Text:      text,
+			Spectator: !isPlayer,
+			SentAt:    now,
+		}
+		room.Chat = append(room.Chat, message)
+		if len(room.Chat) > model.ChatHistorySize {
+			room.Chat = append([]model.ChatMessage(nil), room.Chat[len(room.Chat)-model.ChatHistorySize:]...)
+		}
+
+		// chat is not part of the room's event log, players who come back get the chat history instead
+		payload := map[string]interface{}{
+			"type":    model.MessageTypeChatMessage,
+			"message": "New chat message",
+			"data":    message,
+		}
+		for id, p := range room.Players {
+			if p.Conn != nil && !mutes(room, id, message) {
+				p.Conn.WriteJSON(payload)
+			}
+		}
+		for _, spectator := range room.Spectators {
+			spectator.Conn.WriteJSON(payload)
+		}
+		return nil
+	})
+}
+
+// SendChatHistory sends the room's recent chat to a player or spectator, without the messages they muted
+func (s *RoomService) SendChatHistory(ctx context.Context, roomID string, userID string, conn *ws.Conn) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		history := make([]model.ChatMessage, 0, len(room.Chat))
+		for _, message := range room.Chat {
+			if !mutes(room, userID, message) {
+				history = append(history, message)
+			}
+		}
+		return conn.WriteJSON(map[string]interface{}{
+			"type":    model.MessageTypeChatHistory,
+			"message": "Chat history",
+			"data":    history,
+		})
+	})
+}
+
+// HandleMuteOpponent turns the opponent's chat off or on for the player, a nil muted toggles it.
+// It returns whether the opponent is muted now
+func (s *RoomService) HandleMuteOpponent(ctx context.Context, roomID string, player model.Player, muted *bool) (bool, error) {
+	var isMuted bool
+	err := s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, player); err != nil {
+			return err
+		}
+		isMuted = !room.MutedOpponent[player.User.ID]
+		if muted != nil {
+			isMuted = *muted
+		}
+		if isMuted {
+			room.MutedOpponent[player.User.ID] = true
+		} else {
+			delete(room.MutedOpponent, player.User.ID)
+		}
+		return nil
+	})
+	return isMuted, err
+}
+
+// mutes reports whether the player muted the sender of the message, only a player's opponent can be muted
+func mutes(room *model.Room, playerID string, message model.ChatMessage) bool {
+	if !room.MutedOpponent[playerID] || message.Spectator || message.UserID == playerID {
+		return false
+	}
+	_, senderIsPlayer := room.Players[message.UserID]
+	return senderIsPlayer
+}
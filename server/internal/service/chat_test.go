@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChatLimiterAllow(t *testing.T) {
+	var limiter chatLimiter
+	now := time.Now()
+	for i := 0; i < chatRateLimit; i++ {
+		if !limiter.allow("alice", now) {
+			t.Fatalf("message %d refused within the limit", i+1)
+		}
+	}
+	if limiter.allow("alice", now) {
+		t.Error("message over the limit allowed")
+	}
+	// other users have their own limit
+	if !limiter.allow("bob", now) {
+		t.Error("another user was refused")
+	}
+	if !limiter.allow("alice", now.Add(chatRateWindow)) {
+		t.Error("message refused once the window passed")
+	}
+}
+
+func TestChatLimiterEvictsIdleUsers(t *testing.T) {
+	var limiter chatLimiter
+	start := time.Now()
+	limiter.allow("alice", start)
+	limiter.allow("bob", start.Add(chatRateWindow/2))
+
+	// a window later alice has nothing left to count, bob still does
+	limiter.allow("carol", start.Add(chatRateWindow))
+	if _, kept := limiter.sent["alice"]; kept {
+		t.Error("idle user alice was not evicted")
+	}
+	if _, kept := limiter.sent["bob"]; !kept {
+		t.Error("bob was evicted while their message is still in the window")
+	}
+
+	limiter.allow("carol", start.Add(3*chatRateWindow))
+	if len(limiter.sent) != 1 {
+		t.Errorf("limiter keeps %d users, want only the one who just sent", len(limiter.sent))
+	}
+}
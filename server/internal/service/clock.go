@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// ErrorTimeUp is returned for a move made after the player's clock ran out
+var ErrorTimeUp = errors.New("your time is up")
+
+// startClocks sets up the clocks of a new round from the room's time control, untimed rooms get none
+func (s *RoomService) startClocks(room *model.Room) {
+	stopClock(room, false)
+	room.Clock = nil
+	if room.TimeControl.IsZero() {
+		return
+	}
+
+	playerIDs := make([]string, 0, len(room.Players))
+	for id := range room.Players {
+		playerIDs = append(playerIDs, id)
+	}
+	room.Clock = model.NewClockState(room.TimeControl, playerIDs)
+	s.startClock(room)
+}
+
+// startClock starts the clock of the player to move, when their time runs out the round is lost on time
+func (s *RoomService) startClock(room *model.Room) {
+	clock := room.Clock
+	if clock == nil || room.Status != model.RoomStatusGameStarted {
+		return
+	}
+	game, ok := room.Game.(model.TurnBasedGame)
+	if !ok || game.CurrentPlayerID() == "" {
+		return
+	}
+
+	playerID := game.CurrentPlayerID()
+	if perMove := clock.TimeControl.PerMoveSeconds; perMove > 0 {
+		clock.RemainingMs[playerID] = int64(perMove) * 1000
+	}
+	clock.Running = playerID
+	clock.TurnStartedAt = time.Now()
+
+	roomID := room.ID
+	var timer *time.Timer
+	timer = time.AfterFunc(clock.Remaining(playerID), func() {
+		s.withRoom(s.ctx, roomID, func(room *model.Room) error {
+			// a timer that was stopped after it fired must not end the round
+			if room.ClockTimer != timer {
+				return nil
+			}
+			room.ClockTimer = nil
+			s.timeoutRound(room, playerID)
+			return nil
+		})
+	})
+	room.ClockTimer = timer
+}
+
+// stopClock charges the running player for the time they took and stops their clock,
+// addIncrement tops them up for a completed move
+func stopClock(room *model.Room, addIncrement bool) {
+	if room.ClockTimer != nil {
+		room.ClockTimer.Stop()
+		room.ClockTimer = nil
+	}
+	clock := room.Clock
+	if clock == nil || clock.Running == "" {
+		return
+	}
+
+	playerID := clock.Running
+	remaining := clock.Remaining(playerID) - time.Since(clock.TurnStartedAt)
+	if remaining < 0 {
+		remaining = 0
+	}
+	if addIncrement {
+		remaining += time.Duration(clock.TimeControl.IncrementSeconds) * time.Second
+	}
+	clock.RemainingMs[playerID] = remaining.Milliseconds()
+	clock.Running = ""
+}
+
+// timeRanOut reports whether the player's clock is running and out of time, the timer may not have fired yet
+func timeRanOut(room *model.Room, playerID string) bool {
+	clock := room.Clock
+	if clock == nil || clock.Running != playerID {
+		return false
+	}
+	return time.Since(clock.TurnStartedAt) >= clock.Remaining(playerID)
+}
+
+// timeoutRound ends the round as a win for the opponent of the player whose time ran out
+func (s *RoomService) timeoutRound(room *model.Room, loserID string) {
+	if room.Status != model.RoomStatusGameStarted {
+		return
+	}
+	stopClock(room, false)
+
+	result := model.RoundResult{Reason: model.EndReasonTimeout}
+	loserName := loserID
+	if loser, exists := room.Players[loserID]; exists {
+		loserName = loser.User.Name
+	}
+	if winner, err := s.GetOppositePlayer(s.ctx, room.Players, loserID); err == nil {
+		result.WinnerID = winner.User.ID
+	}
+	s.endRound(room, result)
+
+	broadcast(room, map[string]interface{}{
+		"type":    model.MessageTypeGameOver,
+		"message": fmt.Sprintf("%s ran out of time.", loserName),
+		"data":    room.GetRoomResponse(),
+	})
+}
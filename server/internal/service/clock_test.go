@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// runningClock returns a room whose clock has been running for alice for elapsed with remaining left at the start
+func runningClock(timeControl model.TimeControl, remaining, elapsed time.Duration) *model.Room {
+	room := model.NewRoom()
+	room.Clock = model.NewClockState(timeControl, []string{"alice", "bob"})
+	room.Clock.RemainingMs["alice"] = remaining.Milliseconds()
+	room.Clock.Running = "alice"
+	room.Clock.TurnStartedAt = time.Now().Add(-elapsed)
+	return &room
+}
+
+func TestStopClock(t *testing.T) {
+	bank := model.TimeControl{InitialSeconds: 60, IncrementSeconds: 3}
+	tests := []struct {
+		name         string
+		remaining    time.Duration
+		elapsed      time.Duration
+		addIncrement bool
+		want         time.Duration
+	}{
+		{"charges the time taken", 10 * time.Second, 2 * time.Second, false, 8 * time.Second},
+		{"adds the increment after a move", 10 * time.Second, 2 * time.Second, true, 11 * time.Second},
+		{"never goes below zero", time.Second, 5 * time.Second, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			room := runningClock(bank, tt.remaining, tt.elapsed)
+			stopClock(room, tt.addIncrement)
+			if room.Clock.Running != "" {
+				t.Errorf("clock still running for %s", room.Clock.Running)
+			}
+			got := room.Clock.Remaining("alice")
+			// allow for the time the test itself takes
+			if got > tt.want || got < tt.want-100*time.Millisecond {
+				t.Errorf("remaining = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeRanOut(t *testing.T) {
+	bank := model.TimeControl{InitialSeconds: 60}
+	if timeRanOut(runningClock(bank, 10*time.Second, time.Second), "alice") {
+		t.Error("time ran out with 9 seconds left")
+	}
+	if !timeRanOut(runningClock(bank, time.Second, 2*time.Second), "alice") {
+		t.Error("time did not run out a second past the limit")
+	}
+	// only the running clock can run out
+	if timeRanOut(runningClock(bank, 0, time.Minute), "bob") {
+		t.Error("time ran out for the player who is not to move")
+	}
+	room := model.NewRoom()
+	if timeRanOut(&room, "alice") {
+		t.Error("time ran out in an untimed room")
+	}
+}
+
+func TestPerMoveLimitEndsTheRound(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	roomID, _, _ := startTimedTicTacToe(t, s, model.TimeControl{PerMoveSeconds: 1})
+	first := roomStatus(t, s, roomID).Seating.Order[0]
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		room := roomStatus(t, s, roomID)
+		if room.Status == model.RoomStatusGameOver {
+			if room.Result.Reason != model.EndReasonTimeout || room.Result.WinnerID == first {
+				t.Errorf("result = %+v, want a timeout loss for %s", room.Result, first)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the round did not end when the first player ran out of time")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestLateMoveLosesOnTime(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	roomID, _, _ := startTimedTicTacToe(t, s, model.TimeControl{InitialSeconds: 60})
+
+	// the clock is past its limit before the timer has fired
+	var first string
+	s.withRoom(ctx, roomID, func(room *model.Room) error {
+		first = room.Clock.Running
+		room.Clock.TurnStartedAt = time.Now().Add(-2 * time.Minute)
+		return nil
+	})
+	err := s.HandleGameMove(ctx, roomID, testPlayer(first), tictactoe.Move{Row: 0, Col: 0})
+	if !errors.Is(err, ErrorTimeUp) {
+		t.Fatalf("late move returned %v, want %v", err, ErrorTimeUp)
+	}
+	room := roomStatus(t, s, roomID)
+	if room.Status != model.RoomStatusGameOver || room.Result.Reason != model.EndReasonTimeout {
+		t.Errorf("after a late move status = %s, result = %+v", room.Status, room.Result)
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+var (
+	ErrorGameNotInProgress = errors.New("game is not in progress")
+	ErrorNoDrawOffer       = errors.New("your opponent has not offered a draw")
+)
+
+// HandleResign ends the round as a win for the player's opponent
+func (s *RoomService) HandleResign(ctx context.Context, roomID string, player model.Player) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, player); err != nil {
+			return err
+		}
+		if room.Status != model.RoomStatusGameStarted {
+			return ErrorGameNotInProgress
+		}
+
+		result := model.RoundResult{Reason: model.EndReasonResignation}
+		if winner, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID); err == nil {
+			result.WinnerID = winner.User.ID
+		}
+		s.endRound(room, result)
+
+		broadcast(room, map[string]interface{}{
+			"type":    model.MessageTypeGameOver,
+			"message": fmt.Sprintf("%s resigned.", player.User.Name),
+			"data":    room.GetRoomResponse(),
+		})
+		return nil
+	})
+}
+
+// HandleOfferDraw offers the player's opponent a draw, the offer stands until it is answered or a move is made.
+// A bot turns every offer down
+func (s *RoomService) HandleOfferDraw(ctx context.Context, roomID string, player model.Player) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, player); err != nil {
+			return err
+		}
+		if room.Status != model.RoomStatusGameStarted {
+			return ErrorGameNotInProgress
+		}
+		if room.DrawOfferedBy != "" {
+			return errors.New("a draw offer is already waiting for an answer")
+		}
+
+		oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
+		if err != nil {
+			return err
+		}
+		if oppositePlayer.Bot != nil {
+			sendTo(room, player.User.ID, map[string]interface{}{
+				"type":    model.MessageTypeDrawDeclined,
+				"message": "Your opponent has declined the draw.",
+			})
+			return nil
+		}
+
+		room.DrawOfferedBy = player.User.ID
+		sendTo(room, oppositePlayer.User.ID, map[string]interface{}{
+			"type":    model.MessageTypeDrawOffered,
+			"message": "Your opponent offers a draw.",
+			"data": map[string]interface{}{
+				"player_id": player.User.ID,
+			},
+		})
+		return nil
+	})
+}
+
+// HandleAcceptDraw ends the round as a draw when the player's opponent has offered one
+func (s *RoomService) HandleAcceptDraw(ctx context.Context, roomID string, player model.Player) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requireDrawOffer(room, player); err != nil {
+			return err
+		}
+
+		s.endRound(room, model.RoundResult{Reason: model.EndReasonAgreement})
+
+		broadcast(room, map[string]interface{}{
+			"type":    model.MessageTypeGameOver,
+			"message": "The players agreed to a draw.",
+			"data":    room.GetRoomResponse(),
+		})
+		return nil
+	})
+}
+
+// HandleDeclineDraw turns down the opponent's draw offer and lets them know
+func (s *RoomService) HandleDeclineDraw(ctx context.Context, roomID string, player model.Player) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requireDrawOffer(room, player); err != nil {
+			return err
+		}
+
+		offeredBy := room.DrawOfferedBy
+		room.DrawOfferedBy = ""
+		sendTo(room, offeredBy, map[string]interface{}{
+			"type":    model.MessageTypeDrawDeclined,
+			"message": "Your opponent has declined the draw.",
+		})
+		return nil
+	})
+}
+
+// requireDrawOffer returns an error unless the round is running and the player's opponent has offered a draw
+func requireDrawOffer(room *model.Room, player model.Player) error {
+	if err := requirePlayer(room, player); err != nil {
+		return err
+	}
+	if room.Status != model.RoomStatusGameStarted {
+		return ErrorGameNotInProgress
+	}
+	if room.DrawOfferedBy == "" || room.DrawOfferedBy == player.User.ID {
+		return ErrorNoDrawOffer
+	}
+	return nil
+}
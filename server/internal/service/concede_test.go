@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+func TestResign(t *testing.T) {
+	s, ratingRepo, matchRepo := newTestRoomServiceWithMatches(t)
+	ctx := context.Background()
+	roomID, alice, bob := startTicTacToe(t, s)
+
+	if err := s.HandleResign(ctx, roomID, alice); err != nil {
+		t.Fatalf("HandleResign: %v", err)
+	}
+	room := roomStatus(t, s, roomID)
+	want := model.RoundResult{WinnerID: bob.User.ID, Reason: model.EndReasonResignation}
+	if room.Status != model.RoomStatusGameOver || room.Result == nil || *room.Result != want {
+		t.Fatalf("after resigning status = %s, result = %+v, want %+v", room.Status, room.Result, want)
+	}
+
+	s.Close()
+	if rating, _ := ratingRepo.GetRating(ctx, alice.User.ID, tictactoe.GameType); rating.Losses != 1 {
+		t.Errorf("alice has %d losses after resigning, want 1", rating.Losses)
+	}
+	if rating, _ := ratingRepo.GetRating(ctx, bob.User.ID, tictactoe.GameType); rating.Wins != 1 {
+		t.Errorf("bob has %d wins after alice resigned, want 1", rating.Wins)
+	}
+	matches, _, _ := matchRepo.ListByUser(ctx, alice.User.ID, "", 10, 0)
+	if len(matches) != 1 || matches[0].WinnerID != bob.User.ID || matches[0].EndReason != model.EndReasonResignation {
+		t.Errorf("match history = %+v", matches)
+	}
+}
+
+func TestDrawAccepted(t *testing.T) {
+	s, ratingRepo, matchRepo := newTestRoomServiceWithMatches(t)
+	ctx := context.Background()
+	roomID, alice, bob := startTicTacToe(t, s)
+
+	if err := s.HandleOfferDraw(ctx, roomID, alice); err != nil {
+		t.Fatalf("HandleOfferDraw: %v", err)
+	}
+	if room := roomStatus(t, s, roomID); room.DrawOfferedBy != alice.User.ID {
+		t.Fatalf("draw offered by %q, want %q", room.DrawOfferedBy, alice.User.ID)
+	}
+	if err := s.HandleAcceptDraw(ctx, roomID, bob); err != nil {
+		t.Fatalf("HandleAcceptDraw: %v", err)
+	}
+	room := roomStatus(t, s, roomID)
+	want := model.RoundResult{Reason: model.EndReasonAgreement}
+	if room.Status != model.RoomStatusGameOver || room.Result == nil || *room.Result != want {
+		t.Fatalf("after the draw status = %s, result = %+v, want %+v", room.Status, room.Result, want)
+	}
+
+	s.Close()
+	for _, player := range []model.Player{alice, bob} {
+		if rating, _ := ratingRepo.GetRating(ctx, player.User.ID, tictactoe.GameType); rating.Draws != 1 || rating.Games != 1 {
+			t.Errorf("%s has %d draws in %d games, want 1 in 1", player.User.ID, rating.Draws, rating.Games)
+		}
+	}
+	matches, _, _ := matchRepo.ListByUser(ctx, alice.User.ID, "", 10, 0)
+	if len(matches) != 1 || matches[0].WinnerID != "" || matches[0].EndReason != model.EndReasonAgreement {
+		t.Errorf("match history = %+v", matches)
+	}
+}
+
+func TestDrawOfferAnsweredByOpponentOnly(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	roomID, alice, bob := startTicTacToe(t, s)
+
+	if err := s.HandleAcceptDraw(ctx, roomID, bob); !errors.Is(err, ErrorNoDrawOffer) {
+		t.Errorf("accepting a draw nobody offered returned %v, want %v", err, ErrorNoDrawOffer)
+	}
+	if err := s.HandleOfferDraw(ctx, roomID, alice); err != nil {
+		t.Fatalf("HandleOfferDraw: %v", err)
+	}
+	if err := s.HandleAcceptDraw(ctx, roomID, alice); !errors.Is(err, ErrorNoDrawOffer) {
+		t.Errorf("accepting their own draw offer returned %v, want %v", err, ErrorNoDrawOffer)
+	}
+	if err := s.HandleDeclineDraw(ctx, roomID, alice); !errors.Is(err, ErrorNoDrawOffer) {
+		t.Errorf("declining their own draw offer returned %v, want %v", err, ErrorNoDrawOffer)
+	}
+
+	if err := s.HandleDeclineDraw(ctx, roomID, bob); err != nil {
+		t.Fatalf("HandleDeclineDraw: %v", err)
+	}
+	room := roomStatus(t, s, roomID)
+	if room.Status != model.RoomStatusGameStarted || room.DrawOfferedBy != "" {
+		t.Errorf("after declining status = %s, draw offered by %q", room.Status, room.DrawOfferedBy)
+	}
+}
+
+func TestConcedeOutsideARound(t *testing.T) {
+	actions := []struct {
+		name string
+		act  func(s *RoomService, roomID string, alice, bob model.Player) error
+	}{
+		{name: "resign", act: func(s *RoomService, roomID string, alice, bob model.Player) error {
+			return s.HandleResign(context.Background(), roomID, alice)
+		}},
+		{name: "offer draw", act: func(s *RoomService, roomID string, alice, bob model.Player) error {
+			return s.HandleOfferDraw(context.Background(), roomID, alice)
+		}},
+		{name: "accept draw", act: func(s *RoomService, roomID string, alice, bob model.Player) error {
+			return s.HandleAcceptDraw(context.Background(), roomID, bob)
+		}},
+	}
+	rooms := []struct {
+		name  string
+		setup func(t *testing.T, s *RoomService) (string, model.Player, model.Player)
+	}{
+		{name: "before the game starts", setup: func(t *testing.T, s *RoomService) (string, model.Player, model.Player) {
+			alice, bob := testPlayer("alice"), testPlayer("bob")
+			room, err := s.CreateRoom(context.Background(), alice)
+			if err != nil {
+				t.Fatalf("CreateRoom: %v", err)
+			}
+			if _, err := s.JoinRoom(context.Background(), room.ID, bob); err != nil {
+				t.Fatalf("JoinRoom: %v", err)
+			}
+			return room.ID, alice, bob
+		}},
+		{name: "after the round is over", setup: func(t *testing.T, s *RoomService) (string, model.Player, model.Player) {
+			roomID, alice, bob := startTicTacToe(t, s)
+			playToWin(t, s, roomID)
+			return roomID, alice, bob
+		}},
+	}
+
+	for _, room := range rooms {
+		for _, action := range actions {
+			t.Run(action.name+" "+room.name, func(t *testing.T) {
+				s, _ := newTestRoomService(t)
+				roomID, alice, bob := room.setup(t, s)
+				before := roomStatus(t, s, roomID)
+
+				if err := action.act(s, roomID, alice, bob); !errors.Is(err, ErrorGameNotInProgress) {
+					t.Errorf("returned %v, want %v", err, ErrorGameNotInProgress)
+				}
+				if after := roomStatus(t, s, roomID); after.Status != before.Status || after.DrawOfferedBy != "" {
+					t.Errorf("status %s -> %s, draw offered by %q", before.Status, after.Status, after.DrawOfferedBy)
+				}
+			})
+		}
+	}
+}
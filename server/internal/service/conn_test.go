@@ -0,0 +1,58 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kaviraj-j/duoplay/internal/ws"
+)
+
+// testClient is the client end of a websocket connection served by the test
+type testClient struct {
+	t    *testing.T
+	conn *websocket.Conn
+}
+
+// newTestConn returns a server side connection and the client connected to it, both are closed when the test ends
+func newTestConn(t *testing.T) (*ws.Conn, *testClient) {
+	t.Helper()
+	serverConns := make(chan *ws.Conn, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		serverConns <- ws.NewConn(conn, ws.DefaultConfig())
+	}))
+	t.Cleanup(server.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dialing test server: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	conn := <-serverConns
+	t.Cleanup(func() { conn.Close() })
+	return conn, &testClient{t: t, conn: client}
+}
+
+// next returns the next message the client gets within wait, ok is false when none arrives
+func (c *testClient) next(wait time.Duration) (msg map[string]interface{}, ok bool) {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(wait))
+	_, data, err := c.conn.ReadMessage()
+	if err != nil {
+		return nil, false
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		c.t.Fatalf("client got a message that is not JSON: %s", data)
+	}
+	return msg, true
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// startDisconnectTimer gives a player who dropped mid-game the grace period to reconnect,
+// once it runs out the round is forfeited to the other player. It returns the deadline
+func (s *RoomService) startDisconnectTimer(room *model.Room, playerID string) time.Time {
+	stopDisconnectTimer(room, playerID)
+
+	roomID := room.ID
+	grace := s.config.DisconnectGracePeriod
+	var timer *time.Timer
+	timer = time.AfterFunc(grace, func() {
+		s.withRoom(s.ctx, roomID, func(room *model.Room) error {
+			// a timer that was cancelled after it fired must not end the round
+			if room.DisconnectTimers[playerID] != timer {
+				return nil
+			}
+			delete(room.DisconnectTimers, playerID)
+			s.forfeitRound(room, playerID, "A player did not reconnect in time, the round is forfeited.")
+			return nil
+		})
+	})
+	room.DisconnectTimers[playerID] = timer
+	return time.Now().Add(grace)
+}
+
+// stopDisconnectTimer cancels the player's grace period and reports whether one was running
+func stopDisconnectTimer(room *model.Room, playerID string) bool {
+	timer, exists := room.DisconnectTimers[playerID]
+	if !exists {
+		return false
+	}
+	timer.Stop()
+	delete(room.DisconnectTimers, playerID)
+	return true
+}
+
+// forfeitRound ends the round as a win for the player who stayed, message tells both players why
+func (s *RoomService) forfeitRound(room *model.Room, loserID string, message string) {
+	if room.Status != model.RoomStatusGameStarted {
+		return
+	}
+
+	result := model.RoundResult{Reason: model.EndReasonForfeit}
+	if winner, err := s.GetOppositePlayer(s.ctx, room.Players, loserID); err == nil {
+		result.WinnerID = winner.User.ID
+	}
+	s.endRound(room, result)
+
+	broadcast(room, map[string]interface{}{
+		"type":    model.MessageTypeGameOver,
+		"message": message,
+		"data":    room.GetRoomResponse(),
+	})
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+)
+
+// testGracePeriod keeps the disconnect tests quick, the timers fire well within the test timeouts
+const testGracePeriod = 20 * time.Millisecond
+
+// startGraceRound starts a round of tic tac toe with a short grace period and disconnects alice
+func startGraceRound(t *testing.T) (*RoomService, repository.RatingRepository, string, model.Player, model.Player) {
+	t.Helper()
+	s, ratingRepo := newTestRoomService(t)
+	s.config.DisconnectGracePeriod = testGracePeriod
+	roomID, alice, bob := startTicTacToe(t, s)
+	if err := s.HandlePlayerDisconnected(context.Background(), roomID, alice); err != nil {
+		t.Fatalf("HandlePlayerDisconnected: %v", err)
+	}
+	return s, ratingRepo, roomID, alice, bob
+}
+
+func TestDisconnectGracePeriodExpires(t *testing.T) {
+	s, ratingRepo, roomID, alice, bob := startGraceRound(t)
+	ctx := context.Background()
+
+	deadline := time.Now().Add(100 * testGracePeriod)
+	room := roomStatus(t, s, roomID)
+	for room.Status == model.RoomStatusGameStarted && time.Now().Before(deadline) {
+		time.Sleep(testGracePeriod / 4)
+		room = roomStatus(t, s, roomID)
+	}
+	want := model.RoundResult{WinnerID: bob.User.ID, Reason: model.EndReasonForfeit}
+	if room.Status != model.RoomStatusGameOver || room.Result == nil || *room.Result != want {
+		t.Fatalf("after the grace period status = %s, result = %+v, want %+v", room.Status, room.Result, want)
+	}
+
+	s.Close()
+	if rating, _ := ratingRepo.GetRating(ctx, bob.User.ID, tictactoe.GameType); rating.Wins != 1 {
+		t.Errorf("bob has %d wins after alice forfeited, want 1", rating.Wins)
+	}
+	if rating, _ := ratingRepo.GetRating(ctx, alice.User.ID, tictactoe.GameType); rating.Losses != 1 {
+		t.Errorf("alice has %d losses after forfeiting, want 1", rating.Losses)
+	}
+}
+
+func TestReconnectCancelsForfeit(t *testing.T) {
+	s, _, roomID, alice, _ := startGraceRound(t)
+
+	if _, err := s.JoinRoom(context.Background(), roomID, alice); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	time.Sleep(5 * testGracePeriod)
+
+	if room := roomStatus(t, s, roomID); room.Status != model.RoomStatusGameStarted || room.Result != nil {
+		t.Errorf("after reconnecting status = %s, result = %+v", room.Status, room.Result)
+	}
+}
+
+func TestStaleDisconnectTimerIsIgnored(t *testing.T) {
+	s, _, roomID, alice, _ := startGraceRound(t)
+
+	// the actor is kept busy until the timer has fired, so its forfeit waits behind the reconnect
+	s.withRoom(context.Background(), roomID, func(room *model.Room) error {
+		time.Sleep(5 * testGracePeriod)
+		if !stopDisconnectTimer(room, alice.User.ID) {
+			t.Errorf("no grace period running for alice")
+		}
+		return nil
+	})
+	time.Sleep(5 * testGracePeriod)
+
+	if room := roomStatus(t, s, roomID); room.Status != model.RoomStatusGameStarted || room.Result != nil {
+		t.Errorf("a timer that fired before the reconnect ended the round, status = %s, result = %+v", room.Status, room.Result)
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+)
+
+const (
+	// DefaultMatchPageSize and MaxMatchPageSize bound the pages of a player's match history
+	DefaultMatchPageSize = 20
+	MaxMatchPageSize     = 100
+)
+
+// MatchService keeps the history of finished matches, the room service saves every finished round with it
+type MatchService struct {
+	matchRepo repository.MatchRepository
+}
+
+func NewMatchService(matchRepo repository.MatchRepository) *MatchService {
+	return &MatchService{matchRepo: matchRepo}
+}
+
+// SaveMatch stores the record of a finished match
+func (s *MatchService) SaveMatch(ctx context.Context, match model.Match) error {
+	return s.matchRepo.Save(ctx, match)
+}
+
+// GetMatch returns a finished match with its moves
+func (s *MatchService) GetMatch(ctx context.Context, matchID string) (model.Match, error) {
+	return s.matchRepo.FindByID(ctx, matchID)
+}
+
+// GetUserMatches returns a page of the user's matches, newest first. Pages start at 1 and
+// an empty gameType lists the matches of every game
+func (s *MatchService) GetUserMatches(ctx context.Context, userID string, gameType model.GameType, page, pageSize int) (model.MatchPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultMatchPageSize
+	}
+	if pageSize > MaxMatchPageSize {
+		pageSize = MaxMatchPageSize
+	}
+	matches, total, err := s.matchRepo.ListByUser(ctx, userID, gameType, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return model.MatchPage{}, err
+	}
+	return model.MatchPage{
+		Matches:  matches,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+// newMatchRecord builds the match record of the room's round that just ended
+func newMatchRecord(room *model.Room, endedAt time.Time) model.Match {
+	match := model.Match{
+		ID:        uuid.New().String(),
+		RoomID:    room.ID,
+		GameType:  room.Game.GetType(),
+		Moves:     append([]model.MatchMove(nil), room.RoundMoves...),
+		StartedAt: room.RoundStartedAt,
+		EndedAt:   endedAt,
+	}
+	if room.Result != nil {
+		match.WinnerID = room.Result.WinnerID
+		match.EndReason = room.Result.Reason
+	}
+	for _, seat := range room.Game.GetSeats() {
+		player := room.Players[seat.PlayerID]
+		match.Players = append(match.Players, model.MatchPlayer{
+			UserID: seat.PlayerID,
+			Name:   player.User.Name,
+			Seat:   seat.Index,
+			Mark:   seat.Mark,
+			IsBot:  player.Bot != nil,
+		})
+	}
+	return match
+}
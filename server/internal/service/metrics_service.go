@@ -0,0 +1,76 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/events"
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// MetricsService keeps counters of room events, it learns about them from the event bus
+type MetricsService struct {
+	mu            sync.Mutex
+	startedAt     time.Time
+	events        map[model.RoomEventType]uint64
+	gamesFinished map[model.GameType]uint64
+	endReasons    map[model.EndReason]uint64
+	// activeRooms holds the rooms that have players in them
+	activeRooms map[string]bool
+}
+
+func NewMetricsService(bus *events.Bus) *MetricsService {
+	service := &MetricsService{
+		startedAt:     time.Now(),
+		events:        make(map[model.RoomEventType]uint64),
+		gamesFinished: make(map[model.GameType]uint64),
+		endReasons:    make(map[model.EndReason]uint64),
+		activeRooms:   make(map[string]bool),
+	}
+	bus.Subscribe("metrics", events.DefaultBuffer, service.handleEvent)
+	return service
+}
+
+func (s *MetricsService) handleEvent(event model.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events[event.Type]++
+	switch event.Type {
+	case model.RoomEventTypePlayerJoined:
+		s.activeRooms[event.RoomID] = true
+	case model.RoomEventTypeRoomClosed:
+		delete(s.activeRooms, event.RoomID)
+	case model.RoomEventTypeGameOver:
+		if event.Room.Game != nil {
+			s.gamesFinished[event.Room.Game.Type]++
+		}
+		if event.Room.Result != nil {
+			s.endReasons[event.Room.Result.Reason]++
+		}
+	}
+}
+
+// GetMetrics returns a copy of the counters
+func (s *MetricsService) GetMetrics() model.Metrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metrics := model.Metrics{
+		StartedAt:     s.startedAt.Format(time.RFC3339),
+		Events:        make(map[model.RoomEventType]uint64, len(s.events)),
+		GamesFinished: make(map[model.GameType]uint64, len(s.gamesFinished)),
+		EndReasons:    make(map[model.EndReason]uint64, len(s.endReasons)),
+		ActiveRooms:   len(s.activeRooms),
+	}
+	for eventType, count := range s.events {
+		metrics.Events[eventType] = count
+	}
+	for gameType, count := range s.gamesFinished {
+		metrics.GamesFinished[gameType] = count
+	}
+	for reason, count := range s.endReasons {
+		metrics.EndReasons[reason] = count
+	}
+	return metrics
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/events"
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// waitForActiveRooms waits for the metrics to catch up with the event bus
+func waitForActiveRooms(t *testing.T, metrics *MetricsService, want int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for metrics.GetMetrics().ActiveRooms != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("active rooms = %d, want %d", metrics.GetMetrics().ActiveRooms, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClosedRoomsAreNotActive(t *testing.T) {
+	bus := events.NewBus()
+	defer bus.Close()
+	metrics := NewMetricsService(bus)
+	s, _ := newTestRoomService(t)
+	s.bus = bus
+	ctx := context.Background()
+
+	roomID, alice, _ := startTicTacToe(t, s)
+	playToWin(t, s, roomID)
+	if _, err := s.CreateBotRoom(ctx, testPlayer("carol"), "", ""); err != nil {
+		t.Fatalf("CreateBotRoom: %v", err)
+	}
+	waitForActiveRooms(t, metrics, 2)
+
+	// the finished round leaves the room open until a player closes it
+	if err := s.LeaveRoom(ctx, roomID, alice.User.ID); err != nil {
+		t.Fatalf("LeaveRoom: %v", err)
+	}
+	waitForActiveRooms(t, metrics, 1)
+}
+
+func TestMetricsDropRoomOnClose(t *testing.T) {
+	bus := events.NewBus()
+	defer bus.Close()
+	metrics := NewMetricsService(bus)
+
+	// a room that closes without anyone leaving, such as one whose setup failed, is no longer active
+	metrics.handleEvent(model.Event{Type: model.RoomEventTypePlayerJoined, RoomID: "room", PlayerID: "alice"})
+	metrics.handleEvent(model.Event{Type: model.RoomEventTypeRoomClosed, RoomID: "room"})
+	if active := metrics.GetMetrics().ActiveRooms; active != 0 {
+		t.Errorf("active rooms = %d after the room closed, want 0", active)
+	}
+}
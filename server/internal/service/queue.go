@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// waitSmoothing is how much the latest match counts towards the average wait
+const waitSmoothing = 0.2
+
+// queueStats tracks how long matched players waited, to estimate the wait of the players still queued
+type queueStats struct {
+	mu sync.Mutex
+	// averageWait is a moving average of the waits of recently matched players
+	averageWait time.Duration
+	matches     int
+}
+
+func (q *queueStats) recordWait(wait time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.matches == 0 {
+		q.averageWait = wait
+	} else {
+		q.averageWait = time.Duration(waitSmoothing*float64(wait) + (1-waitSmoothing)*float64(q.averageWait))
+	}
+	q.matches++
+}
+
+// estimate returns how much longer a player who has waited for waited should expect to wait,
+// ok is false until a match has been made
+func (q *queueStats) estimate(waited time.Duration) (remaining time.Duration, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.matches == 0 {
+		return 0, false
+	}
+	if remaining = q.averageWait - waited; remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// NotifyQueuePositions sends every waiting player their place in their game's queue and estimated wait
+func (s *RoomService) NotifyQueuePositions(ctx context.Context) {
+	entries, err := s.queueRepo.GetWaitingPlayers(ctx)
+	if err != nil {
+		return
+	}
+
+	queues := make(map[model.GameType][]model.QueueEntry)
+	for _, entry := range entries {
+		queues[entry.GameType] = append(queues[entry.GameType], entry)
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		queue := queues[entry.GameType]
+		position := 1
+		for position <= len(queue) && queue[position-1].UserID != entry.UserID {
+			position++
+		}
+
+		waited := now.Sub(entry.EnqueuedAt)
+		status := model.QueueStatus{
+			GameType:      entry.GameType,
+			Position:      position,
+			QueueSize:     len(queue),
+			EnqueuedAt:    entry.EnqueuedAt,
+			WaitedSeconds: int(waited.Seconds()),
+		}
+		if remaining, ok := s.queueStats.estimate(waited); ok {
+			seconds := int(remaining.Round(time.Second).Seconds())
+			status.EstimatedWaitSeconds = &seconds
+		}
+
+		// players matched since the snapshot was taken are skipped by the repository
+		s.queueRepo.SendToQueued(ctx, entry.UserID, map[string]interface{}{
+			"type":    model.MessageTypeQueueStatus,
+			"message": "Waiting for an opponent",
+			"data":    status,
+		})
+	}
+}
+
+// ratingWindow is how far from their own rating a player accepts an opponent, it widens the longer they wait
+func (s *RoomService) ratingWindow(entry model.QueueEntry, now time.Time) int {
+	waited := int(now.Sub(entry.EnqueuedAt).Seconds())
+	return s.config.RatingWindow + waited*s.config.RatingWindowGrowth
+}
+
+// choosePair goes through the waiting players oldest first and pairs the first one who has an opponent
+// within rating range with the closest rated of them. Two players are in range when their ratings are
+// no further apart than the wider of their rating windows, so nobody waits forever for a close match
+func (s *RoomService) choosePair(queues map[model.GameType][]model.QueueEntry) (model.QueuePair, bool) {
+	waiting := make([]model.QueueEntry, 0)
+	for _, queue := range queues {
+		waiting = append(waiting, queue...)
+	}
+	sort.SliceStable(waiting, func(i, j int) bool {
+		return waiting[i].EnqueuedAt.Before(waiting[j].EnqueuedAt)
+	})
+
+	now := time.Now()
+	for i, player := range waiting {
+		var best *model.QueuePair
+		bestDiff := 0
+		for j, opponent := range waiting {
+			if i == j {
+				continue
+			}
+			gameType, ok := commonGame(player.GameType, opponent.GameType)
+			if !ok {
+				continue
+			}
+			diff := player.RatingFor(gameType) - opponent.RatingFor(gameType)
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff > max(s.ratingWindow(player, now), s.ratingWindow(opponent, now)) {
+				continue
+			}
+			// the closest rating wins, between equally close opponents the one who waited longer
+			if best == nil || diff < bestDiff {
+				first, second := player, opponent
+				if j < i {
+					first, second = opponent, player
+				}
+				best = &model.QueuePair{First: first, Second: second, GameType: gameType}
+				bestDiff = diff
+			}
+		}
+		if best != nil {
+			return *best, true
+		}
+	}
+	return model.QueuePair{}, false
+}
+
+// commonGame returns the game two queued players can be matched on
+func commonGame(a, b model.GameType) (model.GameType, bool) {
+	switch {
+	case a == b:
+		return a, true
+	case a == model.AnyGame:
+		return b, true
+	case b == model.AnyGame:
+		return a, true
+	}
+	return "", false
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/games/connectfour"
+	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+)
+
+// queued returns the entry of a player rated rating who joined the game's queue waited ago
+func queued(userID string, gameType model.GameType, rating int, waited time.Duration) model.QueueEntry {
+	return model.QueueEntry{
+		UserID:     userID,
+		GameType:   gameType,
+		Rating:     rating,
+		EnqueuedAt: time.Now().Add(-waited),
+	}
+}
+
+func queuesOf(entries ...model.QueueEntry) map[model.GameType][]model.QueueEntry {
+	queues := make(map[model.GameType][]model.QueueEntry)
+	for _, entry := range entries {
+		queues[entry.GameType] = append(queues[entry.GameType], entry)
+	}
+	return queues
+}
+
+func TestChoosePair(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	game := tictactoe.GameType
+
+	tests := []struct {
+		name       string
+		entries    []model.QueueEntry
+		wantOK     bool
+		wantFirst  string
+		wantSecond string
+	}{
+		{
+			name: "longest waiting players first",
+			entries: []model.QueueEntry{
+				queued("carol", game, 1200, 1*time.Second),
+				queued("alice", game, 1200, 3*time.Second),
+				queued("bob", game, 1200, 2*time.Second),
+			},
+			wantOK: true, wantFirst: "alice", wantSecond: "bob",
+		},
+		{
+			name: "closest rating beats a longer wait",
+			entries: []model.QueueEntry{
+				queued("alice", game, 1200, 3*time.Second),
+				queued("bob", game, 1290, 2*time.Second),
+				queued("carol", game, 1210, 1*time.Second),
+			},
+			wantOK: true, wantFirst: "alice", wantSecond: "carol",
+		},
+		{
+			name: "ratings too far apart wait",
+			entries: []model.QueueEntry{
+				queued("alice", game, 1200, 0),
+				queued("bob", game, 1500, 0),
+			},
+			wantOK: false,
+		},
+		{
+			name: "the window widens with the wait",
+			entries: []model.QueueEntry{
+				queued("alice", game, 1200, 30*time.Second),
+				queued("bob", game, 1500, 0),
+			},
+			wantOK: true, wantFirst: "alice", wantSecond: "bob",
+		},
+		{
+			name:    "a lone player waits",
+			entries: []model.QueueEntry{queued("alice", game, 1200, time.Minute)},
+			wantOK:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pair, ok := s.choosePair(queuesOf(tt.entries...))
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if pair.First.UserID != tt.wantFirst || pair.Second.UserID != tt.wantSecond {
+				t.Errorf("paired %s with %s, want %s with %s", pair.First.UserID, pair.Second.UserID, tt.wantFirst, tt.wantSecond)
+			}
+			if pair.GameType != game {
+				t.Errorf("pair game = %s, want %s", pair.GameType, game)
+			}
+		})
+	}
+}
+
+func TestChoosePairKeepsGamesApart(t *testing.T) {
+	s, _ := newTestRoomService(t)
+
+	tests := []struct {
+		name     string
+		entries  []model.QueueEntry
+		wantOK   bool
+		wantGame model.GameType
+	}{
+		{
+			name: "different games are never paired",
+			entries: []model.QueueEntry{
+				queued("alice", tictactoe.GameType, 1200, time.Minute),
+				queued("bob", connectfour.GameType, 1200, time.Minute),
+			},
+			wantOK: false,
+		},
+		{
+			name: "any game plays the other player's game",
+			entries: []model.QueueEntry{
+				queued("alice", model.AnyGame, 1200, time.Minute),
+				queued("bob", connectfour.GameType, 1200, time.Minute),
+			},
+			wantOK: true, wantGame: connectfour.GameType,
+		},
+		{
+			name: "a player queued for another game is passed over",
+			entries: []model.QueueEntry{
+				queued("alice", tictactoe.GameType, 1200, 3*time.Second),
+				queued("bob", connectfour.GameType, 1200, 2*time.Second),
+				queued("carol", tictactoe.GameType, 1200, 1*time.Second),
+			},
+			wantOK: true, wantGame: tictactoe.GameType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pair, ok := s.choosePair(queuesOf(tt.entries...))
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && pair.GameType != tt.wantGame {
+				t.Errorf("paired on %s, want %s", pair.GameType, tt.wantGame)
+			}
+		})
+	}
+}
+
+func TestCommonGame(t *testing.T) {
+	tests := []struct {
+		a, b   model.GameType
+		want   model.GameType
+		wantOK bool
+	}{
+		{tictactoe.GameType, tictactoe.GameType, tictactoe.GameType, true},
+		{tictactoe.GameType, connectfour.GameType, "", false},
+		{model.AnyGame, connectfour.GameType, connectfour.GameType, true},
+		{tictactoe.GameType, model.AnyGame, tictactoe.GameType, true},
+		{model.AnyGame, model.AnyGame, model.AnyGame, true},
+	}
+	for _, tt := range tests {
+		got, ok := commonGame(tt.a, tt.b)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("commonGame(%s, %s) = %s, %v, want %s, %v", tt.a, tt.b, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestQueueStatsEstimate(t *testing.T) {
+	var stats queueStats
+	if _, ok := stats.estimate(0); ok {
+		t.Fatal("estimate before any match reported ok")
+	}
+
+	stats.recordWait(10 * time.Second)
+	if remaining, ok := stats.estimate(4 * time.Second); !ok || remaining != 6*time.Second {
+		t.Errorf("estimate after 4s = %v, %v, want 6s", remaining, ok)
+	}
+	// a player who waited past the average is told they are next
+	if remaining, _ := stats.estimate(time.Minute); remaining != 0 {
+		t.Errorf("estimate past the average = %v, want 0", remaining)
+	}
+
+	// later matches move the average by waitSmoothing
+	stats.recordWait(20 * time.Second)
+	if remaining, _ := stats.estimate(0); remaining != 12*time.Second {
+		t.Errorf("average after 10s and 20s = %v, want 12s", remaining)
+	}
+}
+
+func TestMatchedPlayersLeaveTheQueue(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+
+	matched := make(map[string]<-chan string)
+	for _, id := range []string{"alice", "bob"} {
+		if err := s.userRepo.Create(ctx, &model.User{ID: id, Name: id}); err != nil {
+			t.Fatalf("creating user: %v", err)
+		}
+		conn, _ := newTestConn(t)
+		ch, err := s.JoinQueue(ctx, id, tictactoe.GameType, conn)
+		if err != nil {
+			t.Fatalf("JoinQueue %s: %v", id, err)
+		}
+		matched[id] = ch
+	}
+
+	var roomIDs []string
+	for id, ch := range matched {
+		select {
+		case roomID, ok := <-ch:
+			if !ok {
+				t.Fatalf("%s left the queue without a match", id)
+			}
+			roomIDs = append(roomIDs, roomID)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s was not matched", id)
+		}
+		if s.queueRepo.PlayerExistsInQueue(ctx, id) {
+			t.Errorf("%s is still queued after being matched", id)
+		}
+	}
+	if roomIDs[0] != roomIDs[1] {
+		t.Fatalf("players were matched into different rooms %v", roomIDs)
+	}
+	room := roomStatus(t, s, roomIDs[0])
+	if len(room.Players) != 2 {
+		t.Errorf("match room has %d players", len(room.Players))
+	}
+}
+
+func TestQueueMessagesSkipPlayersNoLongerQueued(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	conn, client := newTestConn(t)
+	if err := s.queueRepo.AddToQueue(ctx, model.QueueEntry{UserID: "alice", GameType: tictactoe.GameType, Conn: conn}); err != nil {
+		t.Fatalf("AddToQueue: %v", err)
+	}
+
+	s.NotifyQueuePositions(ctx)
+	msg, ok := client.next(time.Second)
+	if !ok || msg["type"] != string(model.MessageTypeQueueStatus) {
+		t.Fatalf("queued player got %v, want a queue status", msg)
+	}
+
+	// the player is matched, their connection now belongs to a room
+	if _, err := s.queueRepo.TakeFromQueue(ctx, "alice"); err != nil {
+		t.Fatalf("TakeFromQueue: %v", err)
+	}
+	if err := s.queueRepo.SendToQueued(ctx, "alice", map[string]interface{}{"type": "stale"}); !errors.Is(err, repository.ErrPlayerNotQueued) {
+		t.Errorf("SendToQueued after the player left returned %v, want %v", err, repository.ErrPlayerNotQueued)
+	}
+	s.NotifyQueuePositions(ctx)
+	if msg, ok := client.next(200 * time.Millisecond); ok {
+		t.Errorf("player who left the queue got %v", msg)
+	}
+}
+
+func TestBotOffersSkipPlayersNoLongerQueued(t *testing.T) {
+	s := NewRoomService(repository.NewRoomRepository(), repository.NewQueueRepository(), repository.NewUserRepository(),
+		NewRatingService(repository.NewRatingRepository()), nil, nil, nil, RoomConfig{BotOfferAfter: time.Millisecond})
+	t.Cleanup(s.Close)
+	ctx := context.Background()
+
+	clients := make(map[string]*testClient)
+	for _, id := range []string{"alice", "bob"} {
+		conn, client := newTestConn(t)
+		clients[id] = client
+		entry := model.QueueEntry{UserID: id, GameType: tictactoe.GameType, Conn: conn, EnqueuedAt: time.Now().Add(-time.Minute)}
+		if err := s.queueRepo.AddToQueue(ctx, entry); err != nil {
+			t.Fatalf("AddToQueue: %v", err)
+		}
+	}
+	// alice is matched before the offers go out
+	if _, err := s.queueRepo.TakeFromQueue(ctx, "alice"); err != nil {
+		t.Fatalf("TakeFromQueue: %v", err)
+	}
+
+	s.offerBots(ctx)
+	if msg, ok := clients["bob"].next(time.Second); !ok || msg["type"] != string(model.MessageTypeBotOffer) {
+		t.Errorf("queued player got %v, want a bot offer", msg)
+	}
+	if msg, ok := clients["alice"].next(200 * time.Millisecond); ok {
+		t.Errorf("matched player got %v", msg)
+	}
+	s.botOffersMu.Lock()
+	defer s.botOffersMu.Unlock()
+	if s.botOffers["alice"] || !s.botOffers["bob"] {
+		t.Errorf("bot offers recorded for %v", s.botOffers)
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/games"
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+)
+
+// eloKFactor is the most a rating can move in one game
+const eloKFactor = 32
+
+// RatingService keeps the players' Elo ratings, the room service records every finished round with it
+type RatingService struct {
+	ratingRepo repository.RatingRepository
+}
+
+func NewRatingService(ratingRepo repository.RatingRepository) *RatingService {
+	return &RatingService{ratingRepo: ratingRepo}
+}
+
+// RecordResult updates the ratings of both players of a finished game, winnerID is empty for a draw
+func (s *RatingService) RecordResult(ctx context.Context, gameType model.GameType, playerA, playerB string, winnerID string) error {
+	ratingA, err := s.ratingRepo.GetRating(ctx, playerA, gameType)
+	if err != nil {
+		return err
+	}
+	ratingB, err := s.ratingRepo.GetRating(ctx, playerB, gameType)
+	if err != nil {
+		return err
+	}
+
+	// score is 1 for a win, 0.5 for a draw and 0 for a loss
+	scoreA := 0.5
+	switch winnerID {
+	case playerA:
+		scoreA = 1
+	case playerB:
+		scoreA = 0
+	}
+
+	newA := eloRating(ratingA.Rating, ratingB.Rating, scoreA)
+	newB := eloRating(ratingB.Rating, ratingA.Rating, 1-scoreA)
+	now := time.Now()
+	ratingA = countGame(ratingA, newA, scoreA, now)
+	ratingB = countGame(ratingB, newB, 1-scoreA, now)
+
+	return s.ratingRepo.SaveRatings(ctx, ratingA, ratingB)
+}
+
+// GetUserRatings returns the user's rating in every game they have played
+func (s *RatingService) GetUserRatings(ctx context.Context, userID string) ([]model.Rating, error) {
+	return s.ratingRepo.GetUserRatings(ctx, userID)
+}
+
+// GetQueueRatings returns the user's rating in every registered game, and the rating they are matched
+// by for the given game, which is their average rating when they are open to any game
+func (s *RatingService) GetQueueRatings(ctx context.Context, userID string, gameType model.GameType) (map[model.GameType]int, int, error) {
+	ratings := make(map[model.GameType]int)
+	total := 0
+	for _, info := range games.List() {
+		rating, err := s.ratingRepo.GetRating(ctx, userID, info.Type)
+		if err != nil {
+			return nil, 0, err
+		}
+		ratings[info.Type] = rating.Rating
+		total += rating.Rating
+	}
+
+	if rating, ok := ratings[gameType]; ok {
+		return ratings, rating, nil
+	}
+	if len(ratings) == 0 {
+		return ratings, model.DefaultRating, nil
+	}
+	return ratings, total / len(ratings), nil
+}
+
+// eloRating returns the new rating of a player rated rating who scored score against an opponent rated opponent
+func eloRating(rating, opponent int, score float64) int {
+	expected := 1 / (1 + math.Pow(10, float64(opponent-rating)/400))
+	return rating + int(math.Round(eloKFactor*(score-expected)))
+}
+
+func countGame(rating model.Rating, newRating int, score float64, now time.Time) model.Rating {
+	rating.Rating = newRating
+	rating.Games++
+	switch score {
+	case 1:
+		rating.Wins++
+	case 0:
+		rating.Losses++
+	default:
+		rating.Draws++
+	}
+	rating.UpdatedAt = now
+	return rating
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+)
+
+func TestEloRating(t *testing.T) {
+	tests := []struct {
+		name     string
+		rating   int
+		opponent int
+		score    float64
+		want     int
+	}{
+		{"win between equals", 1200, 1200, 1, 1216},
+		{"loss between equals", 1200, 1200, 0, 1184},
+		{"draw between equals", 1200, 1200, 0.5, 1200},
+		{"underdog win", 1000, 1400, 1, 1029},
+		{"favourite win", 1400, 1000, 1, 1403},
+		{"favourite draw", 1400, 1000, 0.5, 1387},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eloRating(tt.rating, tt.opponent, tt.score); got != tt.want {
+				t.Errorf("eloRating(%d, %d, %v) = %d, want %d", tt.rating, tt.opponent, tt.score, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		winnerID string
+		want     map[string]model.Rating
+	}{
+		{"win", "alice", map[string]model.Rating{
+			"alice": {Rating: 1216, Games: 1, Wins: 1},
+			"bob":   {Rating: 1184, Games: 1, Losses: 1},
+		}},
+		{"draw", "", map[string]model.Rating{
+			"alice": {Rating: 1200, Games: 1, Draws: 1},
+			"bob":   {Rating: 1200, Games: 1, Draws: 1},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := repository.NewRatingRepository()
+			s := NewRatingService(repo)
+			if err := s.RecordResult(ctx, tictactoe.GameType, "alice", "bob", tt.winnerID); err != nil {
+				t.Fatalf("RecordResult: %v", err)
+			}
+			for id, want := range tt.want {
+				got, _ := repo.GetRating(ctx, id, tictactoe.GameType)
+				if got.Rating != want.Rating || got.Games != want.Games || got.Wins != want.Wins ||
+					got.Losses != want.Losses || got.Draws != want.Draws {
+					t.Errorf("%s: got %+v, want %+v", id, got, want)
+				}
+				if got.UpdatedAt.IsZero() {
+					t.Errorf("%s: UpdatedAt not set", id)
+				}
+			}
+		})
+	}
+}
+
+func TestFinishedRoundsAreRated(t *testing.T) {
+	s, ratingRepo := newTestRoomService(t)
+	ctx := context.Background()
+	roomID, _, _ := startTicTacToe(t, s)
+	winnerID := playToWin(t, s, roomID)
+
+	s.Close()
+	rating, _ := ratingRepo.GetRating(ctx, winnerID, tictactoe.GameType)
+	if rating.Wins != 1 || rating.Rating != 1216 {
+		t.Errorf("winner rating after one round = %+v, want 1 win at 1216", rating)
+	}
+}
+
+func TestPracticeRoundsAreNotRated(t *testing.T) {
+	s, ratingRepo := newTestRoomService(t)
+	s.recordRound(finishedRound{
+		roomID:    "practice",
+		gameType:  tictactoe.GameType,
+		playerIDs: []string{"alice", "bot"},
+		winnerID:  "alice",
+		practice:  true,
+	})
+	ratings, _ := ratingRepo.GetUserRatings(context.Background(), "alice")
+	if len(ratings) != 0 {
+		t.Errorf("practice round changed ratings: %+v", ratings)
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+)
+
+var (
+	ErrorInvalidReportStatus = errors.New("invalid report status")
+	ErrorReportResolved      = errors.New("report is already resolved")
+	ErrorResolutionRequired  = errors.New("resolution is required")
+)
+
+// ReportService keeps the reports players make about each other for the moderators
+type ReportService struct {
+	reportRepo repository.ReportRepository
+}
+
+func NewReportService(reportRepo repository.ReportRepository) *ReportService {
+	return &ReportService{reportRepo: reportRepo}
+}
+
+// CreateReport stores a new open report
+func (s *ReportService) CreateReport(ctx context.Context, report model.Report) (model.Report, error) {
+	report.ID = uuid.New().String()
+	report.Status = model.ReportStatusOpen
+	report.CreatedAt = time.Now()
+	if err := s.reportRepo.Create(ctx, report); err != nil {
+		return model.Report{}, err
+	}
+	return report, nil
+}
+
+// ListReports returns the reports with the status, or every report when status is empty
+func (s *ReportService) ListReports(ctx context.Context, status model.ReportStatus) ([]model.Report, error) {
+	if status != "" && !status.IsValid() {
+		return nil, ErrorInvalidReportStatus
+	}
+	return s.reportRepo.List(ctx, status)
+}
+
+// ResolveReport closes an open report with what the moderator decided
+func (s *ReportService) ResolveReport(ctx context.Context, reportID string, moderatorID string, resolution string) (model.Report, error) {
+	resolution = strings.TrimSpace(resolution)
+	if resolution == "" {
+		return model.Report{}, ErrorResolutionRequired
+	}
+
+	report, err := s.reportRepo.FindByID(ctx, reportID)
+	if err != nil {
+		return model.Report{}, err
+	}
+	if report.Status == model.ReportStatusResolved {
+		return model.Report{}, ErrorReportResolved
+	}
+
+	now := time.Now()
+	report.Status = model.ReportStatusResolved
+	report.Resolution = resolution
+	report.ResolvedBy = moderatorID
+	report.ResolvedAt = &now
+	if err := s.reportRepo.Update(ctx, report); err != nil {
+		return model.Report{}, err
+	}
+	return report, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+var (
+	ErrorReportReasonRequired = errors.New("a reason is required to report a player")
+	ErrorReportReasonTooLong  = fmt.Errorf("report reason is longer than %d characters", model.MaxReportReasonLength)
+	ErrorInvalidOffender      = errors.New("reported user is not in the room")
+	ErrorCannotReportSelf     = errors.New("you cannot report yourself")
+)
+
+// ReportPlayer records a report from a player or spectator about someone in the room, along with the room's
+// recent chat and the moves of the current round. A player who leaves offenderID empty reports their opponent
+func (s *RoomService) ReportPlayer(ctx context.Context, roomID string, reporter model.User, offenderID string, reason string) (model.Report, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return model.Report{}, ErrorReportReasonRequired
+	}
+	if utf8.RuneCountInString(reason) > model.MaxReportReasonLength {
+		return model.Report{}, ErrorReportReasonTooLong
+	}
+
+	var report model.Report
+	err := s.withRoom(ctx, roomID, func(room *model.Room) error {
+		_, isPlayer := room.Players[reporter.ID]
+		_, isSpectator := room.Spectators[reporter.ID]
+		if !isPlayer && !isSpectator {
+			return ErrorPlayerNotInRoom
+		}
+		if offenderID == "" && isPlayer {
+			if opponent, err := s.GetOppositePlayer(ctx, room.Players, reporter.ID); err == nil {
+				offenderID = opponent.User.ID
+			}
+		}
+		if offenderID == reporter.ID {
+			return ErrorCannotReportSelf
+		}
+		if !inRoom(room, offenderID) {
+			return ErrorInvalidOffender
+		}
+
+		report = model.Report{
+			RoomID:     room.ID,
+			ReporterID: reporter.ID,
+			OffenderID: offenderID,
+			Reason:     reason,
+			Chat:       append([]model.ChatMessage(nil), room.Chat...),
+		}
+		if room.Game != nil {
+			if undoer, ok := room.Game.(model.Undoer); ok {
+				report.Moves = undoer.History()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Report{}, err
+	}
+
+	return s.reportService.CreateReport(ctx, report)
+}
+
+// inRoom reports whether the user plays, watches or has chatted in the room
+func inRoom(room *model.Room, userID string) bool {
+	if userID == "" {
+		return false
+	}
+	if _, ok := room.Players[userID]; ok {
+		return true
+	}
+	if _, ok := room.Spectators[userID]; ok {
+		return true
+	}
+	for _, message := range room.Chat {
+		if message.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+func TestReportPlayer(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	roomID, alice, bob := startTicTacToe(t, s)
+	conn, _ := newTestConn(t)
+	carol := testPlayer("carol")
+	if _, err := s.WatchRoom(ctx, roomID, model.Spectator{User: carol.User, Conn: conn}); err != nil {
+		t.Fatalf("WatchRoom: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		reporter     model.User
+		offenderID   string
+		reason       string
+		wantErr      error
+		wantOffender string
+	}{
+		{"reason required", alice.User, bob.User.ID, "   ", ErrorReportReasonRequired, ""},
+		{"reason too long", alice.User, bob.User.ID, strings.Repeat("x", model.MaxReportReasonLength+1), ErrorReportReasonTooLong, ""},
+		{"reporter not in the room", model.User{ID: "mallory"}, bob.User.ID, "spam", ErrorPlayerNotInRoom, ""},
+		{"self report", alice.User, alice.User.ID, "spam", ErrorCannotReportSelf, ""},
+		{"offender not in the room", alice.User, "mallory", "spam", ErrorInvalidOffender, ""},
+		{"spectator must name the offender", carol.User, "", "spam", ErrorInvalidOffender, ""},
+		{"player reports the opponent by default", alice.User, "", "spam", nil, bob.User.ID},
+		{"spectator reports a player", carol.User, bob.User.ID, "spam", nil, bob.User.ID},
+		{"player reports a spectator", bob.User, carol.User.ID, "spam", nil, carol.User.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := s.ReportPlayer(ctx, roomID, tt.reporter, tt.offenderID, tt.reason)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReportPlayer returned %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if report.ID == "" || report.RoomID != roomID || report.ReporterID != tt.reporter.ID || report.OffenderID != tt.wantOffender {
+				t.Errorf("report = %+v", report)
+			}
+		})
+	}
+}
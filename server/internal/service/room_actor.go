@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+)
+
+// roomCommandBuffer is how many commands can wait in a room's mailbox before senders block
+const roomCommandBuffer = 32
+
+// roomCommand is a unit of work applied to a room on its actor goroutine
+type roomCommand struct {
+	apply  func(room *model.Room) error
+	result chan error
+}
+
+// roomActor owns a single room, every read and write of the room goes through its
+// mailbox and runs on one goroutine so commands from both players are applied in order
+type roomActor struct {
+	room    *model.Room
+	mailbox chan roomCommand
+	quit    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newRoomActor(room *model.Room) *roomActor {
+	actor := &roomActor{
+		room:    room,
+		mailbox: make(chan roomCommand, roomCommandBuffer),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go actor.run()
+	return actor
+}
+
+func (a *roomActor) run() {
+	defer close(a.done)
+	for {
+		select {
+		case <-a.quit:
+			return
+		case cmd := <-a.mailbox:
+			cmd.result <- cmd.apply(a.room)
+		}
+	}
+}
+
+// do queues fn on the room's mailbox and waits for it to be applied
+func (a *roomActor) do(ctx context.Context, fn func(room *model.Room) error) error {
+	cmd := roomCommand{
+		apply:  fn,
+		result: make(chan error, 1),
+	}
+
+	select {
+	case a.mailbox <- cmd:
+	case <-a.done:
+		return repository.ErrRoomNotFound
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-cmd.result:
+		return err
+	case <-a.done:
+		// the command may have been the one that stopped the actor
+		select {
+		case err := <-cmd.result:
+			return err
+		default:
+			return repository.ErrRoomNotFound
+		}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stop ends the actor, commands still in the mailbox are dropped
+func (a *roomActor) stop() {
+	a.once.Do(func() {
+		close(a.quit)
+	})
+}
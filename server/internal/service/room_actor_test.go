@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+)
+
+// these tests are meant to run with -race, the actor is what keeps them free of data races
+
+func TestRoomActorRunsCommandsOneAtATime(t *testing.T) {
+	room := model.NewRoom()
+	actor := newRoomActor(&room)
+	defer actor.stop()
+
+	const goroutines, commands = 8, 100
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < commands; i++ {
+				actor.do(context.Background(), func(room *model.Room) error {
+					room.UndosUsed["counter"]++
+					return nil
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	var count int
+	actor.do(context.Background(), func(room *model.Room) error {
+		count = room.UndosUsed["counter"]
+		return nil
+	})
+	if count != goroutines*commands {
+		t.Errorf("counter = %d, want %d", count, goroutines*commands)
+	}
+}
+
+func TestRoomActorStopped(t *testing.T) {
+	room := model.NewRoom()
+	actor := newRoomActor(&room)
+
+	// a command that stops its own actor still gets its result back
+	err := actor.do(context.Background(), func(room *model.Room) error {
+		actor.stop()
+		return errors.New("last command")
+	})
+	if err == nil || err.Error() != "last command" {
+		t.Errorf("stopping command returned %v", err)
+	}
+	if err := actor.do(context.Background(), func(room *model.Room) error { return nil }); !errors.Is(err, repository.ErrRoomNotFound) {
+		t.Errorf("command after stop returned %v, want %v", err, repository.ErrRoomNotFound)
+	}
+}
+
+func TestConcurrentJoinsFillOneSeat(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	room, err := s.CreateRoom(ctx, testPlayer("host"))
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+
+	const joiners = 8
+	var joined atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < joiners; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := s.JoinRoom(ctx, room.ID, testPlayer(fmt.Sprintf("guest-%d", i))); err == nil {
+				joined.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if joined.Load() != 1 {
+		t.Errorf("%d guests joined a room with one free seat", joined.Load())
+	}
+	after := roomStatus(t, s, room.ID)
+	if len(after.Players) != 2 || after.Status != model.RoomStatusGameSelection {
+		t.Errorf("room has %d players in status %s", len(after.Players), after.Status)
+	}
+}
+
+func TestConcurrentMovesJoinsAndReads(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	roomID, alice, bob := startTicTacToe(t, s)
+
+	// both players try every cell at once while the other player reconnects, a stranger
+	// tries to join and the room is read, only legal moves may land
+	var accepted atomic.Int32
+	var wg sync.WaitGroup
+	for _, player := range []model.Player{alice, bob} {
+		for row := 0; row < 3; row++ {
+			for col := 0; col < 3; col++ {
+				wg.Add(1)
+				go func(player model.Player, move tictactoe.Move) {
+					defer wg.Done()
+					if s.HandleGameMove(ctx, roomID, player, move) == nil {
+						accepted.Add(1)
+					}
+				}(player, tictactoe.Move{Row: row, Col: col})
+			}
+		}
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if _, err := s.JoinRoom(ctx, roomID, bob); err != nil {
+				t.Errorf("reconnecting player: %v", err)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			if _, err := s.JoinRoom(ctx, roomID, testPlayer(fmt.Sprintf("stranger-%d", i))); err == nil {
+				t.Errorf("a stranger joined a full room")
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			s.GetRoomResponse(ctx, roomID)
+		}()
+	}
+	wg.Wait()
+
+	var history []model.MoveRecord
+	var roundMoves int
+	s.withRoom(ctx, roomID, func(room *model.Room) error {
+		history = room.Game.(model.Undoer).History()
+		roundMoves = len(room.RoundMoves)
+		return nil
+	})
+	if int(accepted.Load()) != len(history) || len(history) != roundMoves {
+		t.Fatalf("%d moves accepted, the game has %d and the match record %d", accepted.Load(), len(history), roundMoves)
+	}
+	// every accepted move came from the player whose turn it was
+	for i := 1; i < len(history); i++ {
+		if history[i].PlayerID == history[i-1].PlayerID {
+			t.Errorf("moves %d and %d were both played by %s", i-1, i, history[i].PlayerID)
+		}
+	}
+}
+
+func TestConcurrentLeaveDuringMoves(t *testing.T) {
+	s, ratingRepo := newTestRoomService(t)
+	ctx := context.Background()
+	roomID, alice, bob := startTicTacToe(t, s)
+
+	var left atomic.Int32
+	var wg sync.WaitGroup
+	for _, player := range []model.Player{alice, bob} {
+		wg.Add(2)
+		go func(player model.Player) {
+			defer wg.Done()
+			for row := 0; row < 3; row++ {
+				for col := 0; col < 3; col++ {
+					s.HandleGameMove(ctx, roomID, player, tictactoe.Move{Row: row, Col: col})
+				}
+			}
+		}(player)
+		go func(player model.Player) {
+			defer wg.Done()
+			err := s.LeaveRoom(ctx, roomID, player.User.ID)
+			if err == nil {
+				left.Add(1)
+			} else if !errors.Is(err, repository.ErrRoomNotFound) {
+				t.Errorf("LeaveRoom: %v", err)
+			}
+		}(player)
+	}
+	wg.Wait()
+
+	if left.Load() != 1 {
+		t.Errorf("%d players left the room, the first leave should close it", left.Load())
+	}
+	if _, err := s.GetRoomResponse(ctx, roomID); !errors.Is(err, repository.ErrRoomNotFound) {
+		t.Errorf("room still open after both players left: %v", err)
+	}
+
+	// the round ended exactly once, by the moves or by the forfeit
+	s.Close()
+	aliceRating, _ := ratingRepo.GetRating(ctx, alice.User.ID, tictactoe.GameType)
+	bobRating, _ := ratingRepo.GetRating(ctx, bob.User.ID, tictactoe.GameType)
+	if aliceRating.Games != 1 || bobRating.Games != 1 {
+		t.Errorf("rated games: alice %d, bob %d, want 1 each", aliceRating.Games, bobRating.Games)
+	}
+}
@@ -4,217 +4,626 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
+	"github.com/kaviraj-j/duoplay/internal/events"
 	"github.com/kaviraj-j/duoplay/internal/games"
-	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
 	"github.com/kaviraj-j/duoplay/internal/model"
 	"github.com/kaviraj-j/duoplay/internal/repository"
+	"github.com/kaviraj-j/duoplay/internal/ws"
 )
 
-var ErrorUserAlreadyInQueue = errors.New("user is already in queue")
+var (
+	ErrorUserAlreadyInQueue = errors.New("user is already in queue")
+	ErrorPlayerNotInRoom    = errors.New("player not found in room")
+	ErrorUnsupportedGame    = errors.New("unsupported game type")
+	ErrorRoundInProgress    = errors.New("a round is in progress")
+	ErrorRoundNotOver       = errors.New("the round is not over yet")
+	ErrorNoReplayRequest    = errors.New("your opponent has not asked for a replay")
+)
+
+const (
+	// defaultDisconnectGracePeriod is used when RoomConfig leaves the grace period unset
+	defaultDisconnectGracePeriod = 30 * time.Second
+	// defaultRatingWindow and defaultRatingWindowGrowth are used when RoomConfig leaves them unset
+	defaultRatingWindow       = 100
+	defaultRatingWindowGrowth = 10
+	// queueRecheckInterval is how often waiting players are matched again as their rating windows widen
+	queueRecheckInterval = time.Second
+	// defaultBotOfferAfter is used when RoomConfig leaves BotOfferAfter unset
+	defaultBotOfferAfter = 30 * time.Second
+	// defaultUndoLimit is used when RoomConfig leaves UndoLimit unset
+	defaultUndoLimit = 3
+)
+
+// RoomConfig holds the room service settings, zero values fall back to the defaults
+type RoomConfig struct {
+	// DisconnectGracePeriod is how long a player who drops mid-game has to reconnect before forfeiting
+	DisconnectGracePeriod time.Duration
+	// RatingWindow is how far apart two players' ratings may be when they have just joined the queue
+	RatingWindow int
+	// RatingWindowGrowth widens a player's rating window by this many points for every second they wait
+	RatingWindowGrowth int
+	// BotOfferAfter is how long a player waits in the queue before being offered a bot, negative turns offers off
+	BotOfferAfter time.Duration
+	// UndoLimit is how many undos every player may have per round, negative turns undos off
+	UndoLimit int
+	// ChatFilter masks the blocked words in chat messages, nil lets every word through
+	ChatFilter *WordFilter
+}
 
 type RoomService struct {
-	roomRepo  repository.RoomRepository
-	userRepo  repository.UserRepository
-	queueRepo repository.QueueRepository
-	ctx       context.Context
-	cancel    context.CancelFunc
+	roomRepo      repository.RoomRepository
+	userRepo      repository.UserRepository
+	queueRepo     repository.QueueRepository
+	ratingService *RatingService
+	matchService  *MatchService
+	reportService *ReportService
+	config        RoomConfig
+	bus           *events.Bus
+	ctx           context.Context
+	cancel        context.CancelFunc
+
+	// queueStats feeds the estimated wait sent to queued players
+	queueStats queueStats
+	// queueSignal wakes the matchmaker when players join the queue
+	queueSignal chan struct{}
+	// botOffers holds the queued players who have been offered a bot
+	botOffers   map[string]bool
+	botOffersMu sync.Mutex
+	// chatLimiter rate limits every user's chat messages across their rooms
+	chatLimiter chatLimiter
+	// rounds carries the finished rounds to the worker that records them, roundsDone is closed once it stops
+	rounds     *roundQueue
+	roundsDone chan struct{}
+
+	// actors own the live rooms, all room reads and writes go through them
+	actors   map[string]*roomActor
+	actorsMu sync.RWMutex
 }
 
-func NewRoomService(roomRepo repository.RoomRepository, queueRepo repository.QueueRepository, userRepo repository.UserRepository) *RoomService {
+func NewRoomService(roomRepo repository.RoomRepository, queueRepo repository.QueueRepository, userRepo repository.UserRepository, ratingService *RatingService, matchService *MatchService, reportService *ReportService, bus *events.Bus, config RoomConfig) *RoomService {
+	if config.DisconnectGracePeriod <= 0 {
+		config.DisconnectGracePeriod = defaultDisconnectGracePeriod
+	}
+	if config.RatingWindow <= 0 {
+		config.RatingWindow = defaultRatingWindow
+	}
+	if config.RatingWindowGrowth <= 0 {
+		config.RatingWindowGrowth = defaultRatingWindowGrowth
+	}
+	if config.BotOfferAfter == 0 {
+		config.BotOfferAfter = defaultBotOfferAfter
+	}
+	if config.UndoLimit == 0 {
+		config.UndoLimit = defaultUndoLimit
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	service := &RoomService{
 		roomRepo:  roomRepo,
 		userRepo:  userRepo,
 		queueRepo: queueRepo,
-		ctx:       ctx,
-		cancel:    cancel,
+		config:    config,
+
+		ratingService: ratingService,
+		matchService:  matchService,
+		reportService: reportService,
+		bus:           bus,
+		ctx:           ctx,
+		cancel:        cancel,
+		actors:        make(map[string]*roomActor),
+
+		queueSignal: make(chan struct{}, 1),
+		botOffers:   make(map[string]bool),
+		rounds:      newRoundQueue(),
+		roundsDone:  make(chan struct{}),
 	}
 
 	// Start the centralized queue monitor
 	go service.monitorQueue(ctx)
+	// finished rounds are recorded off the room actors
+	go service.recordRounds(ctx)
 
 	return service
 }
 
-func (s *RoomService) CreateRoom(ctx context.Context) (model.Room, error) {
+// Close stops the service's background work, the finished rounds still waiting are recorded first
+func (s *RoomService) Close() {
+	s.cancel()
+	<-s.roundsDone
+}
+
+// registerRoom stores a new room and starts the actor that owns it
+func (s *RoomService) registerRoom(ctx context.Context, room model.Room) error {
+	if err := s.roomRepo.CreateRoom(ctx, room); err != nil {
+		return err
+	}
+	storedRoom, err := s.roomRepo.GetRoomByID(ctx, room.ID)
+	if err != nil {
+		return err
+	}
+
+	s.actorsMu.Lock()
+	s.actors[room.ID] = newRoomActor(storedRoom)
+	s.actorsMu.Unlock()
+	return nil
+}
+
+// withRoom runs fn on the actor that owns the room, fn must not keep the room pointer after it returns
+func (s *RoomService) withRoom(ctx context.Context, roomID string, fn func(room *model.Room) error) error {
+	s.actorsMu.RLock()
+	actor, exists := s.actors[roomID]
+	s.actorsMu.RUnlock()
+	if !exists {
+		return repository.ErrRoomNotFound
+	}
+	return actor.do(ctx, fn)
+}
+
+// removeRoom deletes the room, stops its actor and publishes RoomEventTypeRoomClosed, it is safe to call
+// from inside a room command
+func (s *RoomService) removeRoom(ctx context.Context, roomID string) error {
+	s.actorsMu.Lock()
+	actor, exists := s.actors[roomID]
+	delete(s.actors, roomID)
+	s.actorsMu.Unlock()
+	if exists {
+		actor.stop()
+	}
+	if s.bus != nil {
+		s.bus.Publish(model.Event{Type: model.RoomEventTypeRoomClosed, RoomID: roomID, Time: time.Now()})
+	}
+	return s.roomRepo.DeleteRoom(ctx, roomID)
+}
+
+// CreateRoom creates a new room hosted by the given player
+func (s *RoomService) CreateRoom(ctx context.Context, host model.Player) (model.RoomResponse, error) {
 	room := model.NewRoom()
-	err := s.roomRepo.CreateRoom(ctx, room)
+	if err := s.registerRoom(ctx, room); err != nil {
+		return model.RoomResponse{}, err
+	}
+
+	var roomResponse model.RoomResponse
+	err := s.withRoom(ctx, room.ID, func(room *model.Room) error {
+		if err := s.addPlayer(ctx, room, host); err != nil {
+			return err
+		}
+		roomResponse = room.GetRoomResponse()
+		return nil
+	})
 	if err != nil {
-		return model.Room{}, err
+		s.removeRoom(ctx, room.ID)
+		return model.RoomResponse{}, err
 	}
-	return room, nil
+	return roomResponse, nil
 }
 
-func (s *RoomService) AddPlayer(ctx context.Context, roomID string, player model.Player) error {
-	return s.roomRepo.AddPlayerToRoom(ctx, roomID, player)
+// addPlayer adds a player to the room, or swaps in the new connection of a player who reconnects
+func (s *RoomService) addPlayer(ctx context.Context, room *model.Room, player model.Player) error {
+	_, reconnecting := room.Players[player.User.ID]
+	spectator, watching := room.Spectators[player.User.ID]
+	if err := s.roomRepo.AddPlayerToRoom(ctx, room.ID, player); err != nil {
+		return err
+	}
+	// a spectator who takes a seat plays on their player connection, the watch connection is closed
+	if watching {
+		s.closeWatchConnection(room, spectator)
+	}
+	// the first player to join hosts the room
+	if room.Seating.HostID == "" {
+		room.Seating.HostID = player.User.ID
+	}
+	if !reconnecting {
+		s.publish(room, model.RoomEventTypePlayerJoined, player.User.ID, nil)
+	}
+	return nil
 }
 
-func (s *RoomService) GetRoom(ctx context.Context, roomID string) (*model.Room, error) {
-	return s.roomRepo.GetRoomByID(ctx, roomID)
+// JoinRoom adds the player to the room, lets the other player know and returns the joined room
+func (s *RoomService) JoinRoom(ctx context.Context, roomID string, player model.Player) (model.RoomResponse, error) {
+	var roomResponse model.RoomResponse
+	err := s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := s.addPlayer(ctx, room, player); err != nil {
+			return err
+		}
+		// a player who is back in time keeps playing the round they dropped out of
+		reconnected := stopDisconnectTimer(room, player.User.ID)
+
+		// change room status to game selection once both players are in
+		if room.Status == model.RoomStatusWaitingForPlayer && len(room.Players) == 2 {
+			room.Status = model.RoomStatusGameSelection
+		}
+		roomResponse = room.GetRoomResponse()
+
+		// Send message to the other player
+		if otherPlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID); err == nil {
+			if reconnected {
+				sendTo(room, otherPlayer.User.ID, map[string]interface{}{
+					"type":    model.MessageTypeOpponentReconnected,
+					"message": "Your opponent has reconnected.",
+					"data": map[string]interface{}{
+						"user": player.User,
+					},
+				})
+			} else {
+				sendTo(room, otherPlayer.User.ID, map[string]interface{}{
+					"type":    "joined_room",
+					"message": "Opponent has joined room",
+				})
+			}
+		}
+		return nil
+	})
+	return roomResponse, err
+}
+
+// GetRoomResponse returns a snapshot of the room that is safe to use outside its actor
+func (s *RoomService) GetRoomResponse(ctx context.Context, roomID string) (model.RoomResponse, error) {
+	var roomResponse model.RoomResponse
+	err := s.withRoom(ctx, roomID, func(room *model.Room) error {
+		roomResponse = room.GetRoomResponse()
+		return nil
+	})
+	return roomResponse, err
 }
 
-func (s *RoomService) UpdateRoom(ctx context.Context, room model.Room) error {
-	return s.roomRepo.UpdateRoom(ctx, room)
+// IsPlayerInRoom reports whether the user is one of the room's players
+func (s *RoomService) IsPlayerInRoom(ctx context.Context, roomID string, userID string) (bool, error) {
+	var exists bool
+	err := s.withRoom(ctx, roomID, func(room *model.Room) error {
+		_, exists = room.Players[userID]
+		return nil
+	})
+	return exists, err
 }
 
 func (s *RoomService) StartGame(ctx context.Context, roomID string) error {
-	room, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if room.Game == nil {
+			return fmt.Errorf("no game chosen")
+		}
+		if err := s.startRound(room); err != nil {
+			return fmt.Errorf("error starting game")
+		}
+		return nil
+	})
+}
+
+// startRound seats the players for a new round by the room's seat policy and starts the room's game
+func (s *RoomService) startRound(room *model.Room) error {
+	players, err := assignSeats(room)
 	if err != nil {
-		return fmt.Errorf("error getting room")
+		return err
 	}
-	err = room.Game.Start()
-	if err != nil {
-		return fmt.Errorf("error starting game")
+	if err := room.Game.SeatPlayers(players); err != nil {
+		return fmt.Errorf("failed to seat players: %v", err)
+	}
+	if err := room.Game.Start(); err != nil {
+		return fmt.Errorf("failed to start game: %v", err)
 	}
 
 	// update the room status to game started
 	room.Status = model.RoomStatusGameStarted
-	err = s.roomRepo.UpdateRoom(ctx, *room)
-	if err != nil {
-		return err
-	}
+	room.Result = nil
+	room.RoundStartedAt = time.Now()
+	room.RoundMoves = nil
+	room.DrawOfferedBy = ""
+	room.UndoRequestedBy = ""
+	room.ReplayRequestedBy = ""
+	room.UndosUsed = make(map[string]int)
+	s.startClocks(room)
+	s.publish(room, model.RoomEventTypeGameStarted, "", nil)
+	// a bot in the first seat opens the round
+	s.scheduleBotTurn(room)
 	return nil
 }
 
-func (s *RoomService) GetGame(ctx context.Context, roomID string) (*model.Game, error) {
-	return s.roomRepo.GetGame(ctx, roomID)
-}
+// JoinQueue puts the player at the back of the queue for the game, or of the queue for any game
+// when gameType is model.AnyGame. The returned channel receives the room ID once they are matched
+// and is closed if they leave the queue without a match
+func (s *RoomService) JoinQueue(ctx context.Context, userID string, gameType model.GameType, conn *ws.Conn) (<-chan string, error) {
+	if gameType == "" {
+		gameType = model.AnyGame
+	}
+	if _, exists := games.Lookup(gameType); !exists && gameType != model.AnyGame {
+		return nil, ErrorUnsupportedGame
+	}
 
-func (s *RoomService) JoinQueue(ctx context.Context, userID string, conn *websocket.Conn) error {
 	// check if user is already in queue
 	isInQueue := s.queueRepo.PlayerExistsInQueue(ctx, userID)
 
 	if isInQueue {
-		return ErrorUserAlreadyInQueue
+		return nil, ErrorUserAlreadyInQueue
+	}
+
+	// players are matched by their rating in the game they queue for
+	ratings, rating, err := s.ratingService.GetQueueRatings(ctx, userID, gameType)
+	if err != nil {
+		return nil, err
 	}
 
-	// Add player to queue
-	err := s.queueRepo.AddToQueue(ctx, userID, conn)
+	// Add player to the back of the queue
+	matched := make(chan string, 1)
+	err = s.queueRepo.AddToQueue(ctx, model.QueueEntry{
+		UserID:     userID,
+		GameType:   gameType,
+		Conn:       conn,
+		EnqueuedAt: time.Now(),
+		Rating:     rating,
+		Ratings:    ratings,
+		Matched:    matched,
+	})
+	if errors.Is(err, repository.ErrPlayerAlreadyQueued) {
+		return nil, ErrorUserAlreadyInQueue
+	}
 	if err != nil {
+		return nil, err
+	}
+
+	// a new player may complete a pair
+	s.signalQueue()
+	return matched, nil
+}
+
+func (s *RoomService) RemoveFromQueue(ctx context.Context, userID string) error {
+	if err := s.queueRepo.RemoveFromQueue(ctx, userID); err != nil {
 		return err
 	}
+	// everyone behind the player moves up
+	s.NotifyQueuePositions(ctx)
 	return nil
 }
 
-func (s *RoomService) RemoveFromQueue(ctx context.Context, userID string) error {
-	return s.queueRepo.RemoveFromQueue(ctx, userID)
+// signalQueue wakes the matchmaker, signals sent while it is busy are merged into one
+func (s *RoomService) signalQueue() {
+	select {
+	case s.queueSignal <- struct{}{}:
+	default:
+	}
 }
 
+// monitorQueue matches waiting players whenever the queue changes, and again every
+// queueRecheckInterval because the rating windows of waiting players keep widening
 func (s *RoomService) monitorQueue(ctx context.Context) {
-	ticker := time.NewTicker(100 * time.Millisecond)
+	ticker := time.NewTicker(queueRecheckInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-s.queueSignal:
+			s.matchWaitingPlayers(ctx)
 		case <-ticker.C:
-			// Get all waiting players
-			waitingPlayers, err := s.queueRepo.GetWaitingPlayers(ctx)
-			if err != nil {
-				continue
-			}
-
-			// If we have 2 or more players, create matches
-			for len(waitingPlayers) >= 2 {
-				player1ID := waitingPlayers[0]
-				player2ID := waitingPlayers[1]
-
-				// Try to create a match
-				room, err := s.CreateMatch(ctx, player1ID, player2ID)
-				if err != nil {
-					continue
-				}
-
-				// Log successful match
-				fmt.Printf("Match created: Room %s with players %s and %s\n", room.ID, player1ID, player2ID)
-			}
+			s.matchWaitingPlayers(ctx)
+			s.offerBots(ctx)
 		}
 	}
 }
 
-func (s *RoomService) CreateMatch(ctx context.Context, player1ID, player2ID string) (*model.Room, error) {
-	// Create a new room
-	room := model.NewRoom()
+// matchWaitingPlayers pairs the players until no more pairs can be made, see choosePair
+func (s *RoomService) matchWaitingPlayers(ctx context.Context) {
+	matched := false
+	for {
+		pair, err := s.queueRepo.DequeuePair(ctx, s.choosePair)
+		if err != nil {
+			break
+		}
 
-	// Get player connections from queue
-	player1Conn, err := s.queueRepo.GetPlayerConnection(ctx, player1ID)
-	if err != nil {
-		return nil, fmt.Errorf("player 1 connection not found: %v", err)
+		now := time.Now()
+		room, err := s.CreateMatch(ctx, pair)
+		if err != nil {
+			fmt.Printf("Failed to create match for players %s and %s: %v\n", pair.First.UserID, pair.Second.UserID, err)
+			continue
+		}
+		matched = true
+		s.queueStats.recordWait(now.Sub(pair.First.EnqueuedAt))
+		s.queueStats.recordWait(now.Sub(pair.Second.EnqueuedAt))
+
+		// Log successful match
+		fmt.Printf("Match created: Room %s with players %s and %s for %s\n", room.ID, pair.First.UserID, pair.Second.UserID, pair.GameType)
+	}
+	if matched {
+		s.NotifyQueuePositions(ctx)
 	}
+}
 
-	player2Conn, err := s.queueRepo.GetPlayerConnection(ctx, player2ID)
+// CreateMatch puts two players taken off the queue into a new room and hands their queue
+// connections over to it, if the room cannot be created both players are disconnected
+func (s *RoomService) CreateMatch(ctx context.Context, pair model.QueuePair) (*model.RoomResponse, error) {
+	roomResponse, err := s.createMatchRoom(ctx, pair)
 	if err != nil {
-		return nil, fmt.Errorf("player 2 connection not found: %v", err)
+		for _, entry := range []model.QueueEntry{pair.First, pair.Second} {
+			entry.Conn.WriteJSON(map[string]interface{}{
+				"type":    model.MessageTypeError,
+				"message": "Failed to create match",
+			})
+			entry.Conn.Close()
+			close(entry.Matched)
+		}
+		return nil, err
 	}
+	return roomResponse, nil
+}
+
+func (s *RoomService) createMatchRoom(ctx context.Context, pair model.QueuePair) (*model.RoomResponse, error) {
+	player1Entry, player2Entry := pair.First, pair.Second
 
-	// Create player objects
-	user1, err := s.userRepo.FindByID(ctx, player1ID)
+	// Create a new room
+	room := model.NewRoom()
+
+	// Create player objects, the queue connections now serve the room
+	user1, err := s.userRepo.FindByID(ctx, player1Entry.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("user 1 not found: %v", err)
 	}
 	player1 := model.Player{
 		User: *user1,
-		Conn: player1Conn,
+		Conn: player1Entry.Conn,
 	}
 
-	user2, err := s.userRepo.FindByID(ctx, player2ID)
+	user2, err := s.userRepo.FindByID(ctx, player2Entry.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("user 2 not found: %v", err)
 	}
 	player2 := model.Player{
 		User: *user2,
-		Conn: player2Conn,
+		Conn: player2Entry.Conn,
 	}
 
-	// Add players to room
-	room.Players[player1ID] = player1
-	room.Players[player2ID] = player2
+	// Add players to room, the player who waited longest hosts it
+	room.Players[player1.User.ID] = player1
+	room.Players[player2.User.ID] = player2
+	room.Seating.HostID = player1.User.ID
 
-	// Create a game instance
-	game := tictactoe.NewTicTacToe()
-	room.Game = game
-
-	// Save room to repository
-	err = s.roomRepo.CreateRoom(ctx, room)
-	if err != nil {
-		return nil, err
+	// Players matched on a game skip game selection, otherwise the game is created from the
+	// registry once both players agree on it
+	var game model.Game
+	if pair.GameType != model.AnyGame {
+		game, err = games.CreateGameFromName(string(pair.GameType))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create game: %v", err)
+		}
+		room.GameSelection.PlayerChoices[player1.User.ID] = pair.GameType
+		room.GameSelection.PlayerChoices[player2.User.ID] = pair.GameType
 	}
 
 	// Update room status to game selection
 	room.Status = model.RoomStatusGameSelection
-	err = s.roomRepo.UpdateRoom(ctx, room)
+
+	// Save room to repository, from here on the room is owned by its actor
+	err = s.registerRoom(ctx, room)
 	if err != nil {
 		return nil, err
 	}
 
-	// Notify both players about the match
-	player1Conn.WriteJSON(gin.H{
-		"type":    "match_found",
-		"room_id": room.ID,
-		"message": "Match found! Game starting...",
+	var roomResponse model.RoomResponse
+	err = s.withRoom(ctx, room.ID, func(room *model.Room) error {
+		for _, p := range room.Players {
+			s.publish(room, model.RoomEventTypePlayerJoined, p.User.ID, nil)
+		}
+		if game != nil {
+			room.Game = game
+			if err := s.startRound(room); err != nil {
+				return err
+			}
+		}
+		roomResponse = room.GetRoomResponse()
+
+		// the queue connections switch to the room before anything is sent in it
+		player1Entry.Matched <- room.ID
+		player2Entry.Matched <- room.ID
+
+		// Notify both players about the match, with the ratings they were matched by
+		broadcast(room, gin.H{
+			"type":    "match_found",
+			"room_id": room.ID,
+			"message": "Match found! Game starting...",
+			"data":    roomResponse,
+			"ratings": map[string]int{
+				player1Entry.UserID: player1Entry.RatingFor(pair.GameType),
+				player2Entry.UserID: player2Entry.RatingFor(pair.GameType),
+			},
+		})
+		if game != nil {
+			broadcast(room, map[string]interface{}{
+				"type":      model.MessageTypeStartGame,
+				"game_type": game.GetType(),
+				"room_id":   room.ID,
+				"data":      roomResponse,
+			})
+		}
+		return nil
 	})
+	if err != nil {
+		s.removeRoom(ctx, room.ID)
+		return nil, err
+	}
+
+	return &roomResponse, nil
+}
 
-	player2Conn.WriteJSON(gin.H{
-		"type":    "match_found",
-		"room_id": room.ID,
-		"message": "Match found! Game starting...",
+// LeaveRoom closes the room for both players and lets the other player know,
+// leaving in the middle of a round forfeits it
+func (s *RoomService) LeaveRoom(ctx context.Context, roomID string, userID string) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		s.forfeitRound(room, userID, "Your opponent left the room, the round is forfeited.")
+		if oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, userID); err == nil {
+			sendTo(room, oppositePlayer.User.ID, map[string]interface{}{
+				"type":    model.MessageTypeOpponentLeft,
+				"message": "Opponent has left the room",
+			})
+		}
+		s.publish(room, model.RoomEventTypePlayerLeft, userID, nil)
+		closeSpectators(room)
+		return s.removeRoom(ctx, room.ID)
 	})
+}
+
+// HandlePlayerDisconnected drops the player's dead connection from the room and tells the other player,
+// a connection that was already replaced by a reconnect is ignored
+func (s *RoomService) HandlePlayerDisconnected(ctx context.Context, roomID string, player model.Player) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		current, exists := room.Players[player.User.ID]
+		if !exists || current.Conn != player.Conn {
+			return nil
+		}
+		current.Conn = nil
+		room.Players[player.User.ID] = current
+
+		data := map[string]interface{}{
+			"user": player.User,
+		}
+		// mid-game the player has a grace period to come back before the round is forfeited
+		if room.Status == model.RoomStatusGameStarted {
+			deadline := s.startDisconnectTimer(room, player.User.ID)
+			data["deadline"] = deadline
+			data["seconds_remaining"] = int(s.config.DisconnectGracePeriod.Seconds())
+		}
 
-	return &room, nil
+		if oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID); err == nil {
+			sendTo(room, oppositePlayer.User.ID, map[string]interface{}{
+				"type":    model.MessageTypeOpponentDisconnected,
+				"message": "Your opponent has disconnected.",
+				"data":    data,
+			})
+		}
+		return nil
+	})
 }
 
-func (s *RoomService) LeaveRoom(ctx context.Context, roomID string) error {
-	_, err := s.roomRepo.GetRoomByID(ctx, roomID)
-	if err != nil {
-		return fmt.Errorf("error getting room")
-	}
+// ResumeSession sends the player the events after lastSeq that were meant for them, or a snapshot
+// of the whole room when those events are no longer in the log, nothing else is sent in between
+func (s *RoomService) ResumeSession(ctx context.Context, roomID string, player model.Player, lastSeq uint64) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, player); err != nil {
+			return err
+		}
 
-	return s.roomRepo.DeleteRoom(ctx, roomID)
+		events, ok := room.Events.Since(player.User.ID, lastSeq)
+		if !ok {
+			return player.Conn.WriteJSON(map[string]interface{}{
+				"type":    model.MessageTypeSnapshot,
+				"message": "Missed events are no longer available, here is the current room.",
+				"data":    room.GetRoomResponse(),
+			})
+		}
+
+		for _, event := range events {
+			player.Conn.WriteJSON(event.Payload)
+		}
+		return player.Conn.WriteJSON(map[string]interface{}{
+			"type":    model.MessageTypeResumed,
+			"message": "Session resumed.",
+			"data": map[string]interface{}{
+				"last_seq": room.Events.LastSeq,
+				"replayed": len(events),
+			},
+		})
+	})
 }
 
 func (s *RoomService) GetOppositePlayer(ctx context.Context, roomPlayers map[string]model.Player, playerId string) (model.Player, error) {
@@ -226,233 +635,469 @@ func (s *RoomService) GetOppositePlayer(ctx context.Context, roomPlayers map[str
 	return model.Player{}, errors.New("opposite player not found")
 }
 
-// handlePlayerJoinedRoom notifies the other player in the room that a player has joined
-func (s *RoomService) HandlePlayerJoinedRoom(ctx context.Context, room *model.Room, joinedPlayer model.Player) error {
-	// Find the opposite player
-	oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, joinedPlayer.User.ID)
+// endRound marks the room's round as over with its result
+func (s *RoomService) endRound(room *model.Room, result model.RoundResult) {
+	room.Status = model.RoomStatusGameOver
+	room.Result = &result
+	stopClock(room, false)
+	room.DrawOfferedBy = ""
+	room.UndoRequestedBy = ""
+	for playerID := range room.DisconnectTimers {
+		stopDisconnectTimer(room, playerID)
+	}
+	// the match history and the ratings must not miss a round, so it goes through the round queue
+	// rather than the lossy event bus, the event carries the same match record for its subscribers
+	match := newMatchRecord(room, time.Now())
+	s.queueFinishedRound(room, match)
+	s.publish(room, model.RoomEventTypeGameOver, "", match)
+}
+
+// publish puts a room event on the event bus, the bus never blocks the room
+func (s *RoomService) publish(room *model.Room, eventType model.RoomEventType, playerID string, payload interface{}) {
+	if s.bus == nil {
+		return
+	}
+	s.bus.Publish(model.Event{
+		Type:     eventType,
+		RoomID:   room.ID,
+		PlayerID: playerID,
+		Room:     room.GetRoomResponse(),
+		Payload:  payload,
+		Time:     time.Now(),
+	})
+}
+
+// broadcast logs msg as the room's next event and sends it to every connected player and spectator.
+// Client messages do not go through the event bus: the bus drops events for a subscriber that falls
+// behind and runs it on its own goroutine, while a client message must take its seq from the room's
+// event log in the order the actor applied the commands. Sending from the actor keeps both, and
+// WriteJSON only queues the message on the connection so the room never waits on a slow client
+func broadcast(room *model.Room, msg map[string]interface{}) {
+	event, err := room.Events.Append("", msg)
 	if err != nil {
-		return err
+		fmt.Printf("Failed to log event for room %s: %v\n", room.ID, err)
+		return
+	}
+	for _, p := range room.Players {
+		if p.Conn != nil {
+			p.Conn.WriteJSON(event.Payload)
+		}
+	}
+	for _, spectator := range room.Spectators {
+		spectator.Conn.WriteJSON(event.Payload)
+	}
+}
+
+// sendTo logs msg as the room's next event for one player and sends it if they are connected,
+// a player who is away gets it when they resume. Like broadcast it sends from the actor, not the bus
+func sendTo(room *model.Room, playerID string, msg map[string]interface{}) {
+	event, err := room.Events.Append(playerID, msg)
+	if err != nil {
+		fmt.Printf("Failed to log event for room %s: %v\n", room.ID, err)
+		return
+	}
+	if p, exists := room.Players[playerID]; exists && p.Conn != nil {
+		p.Conn.WriteJSON(event.Payload)
+	}
+}
+
+// requirePlayer returns ErrorPlayerNotInRoom unless the player is seated in the room
+func requirePlayer(room *model.Room, player model.Player) error {
+	if _, exists := room.Players[player.User.ID]; !exists {
+		return ErrorPlayerNotInRoom
 	}
-	if oppositePlayer.Conn != nil {
-		err := oppositePlayer.Conn.WriteJSON(map[string]interface{}{
+	return nil
+}
+
+// HandlePlayerJoinedRoom notifies the other player in the room that a player has joined
+func (s *RoomService) HandlePlayerJoinedRoom(ctx context.Context, roomID string, joinedPlayer model.Player) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, joinedPlayer); err != nil {
+			return err
+		}
+
+		// Find the opposite player
+		oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, joinedPlayer.User.ID)
+		if err != nil {
+			return err
+		}
+		sendTo(room, oppositePlayer.User.ID, map[string]interface{}{
 			"type":    "joined_room",
 			"message": "A player has joined your room.",
 			"data": map[string]interface{}{
 				"user": joinedPlayer.User,
 			},
 		})
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+		return nil
+	})
 }
 
-// HandleGameChosen handles when a player chooses a game
-func (s *RoomService) HandleGameChosen(ctx context.Context, room *model.Room, player model.Player, gameType model.GameType) error {
-	// Record the player's game choice
-	room.GameSelection.PlayerChoices[player.User.ID] = gameType
-
-	// Notify the opposite player about the game choice
-	oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
-	if err != nil {
+// HandleGameChosen handles when a player chooses a game, the time control is proposed with it
+// and the zero time control proposes an untimed game
+func (s *RoomService) HandleGameChosen(ctx context.Context, roomID string, player model.Player, gameType model.GameType, timeControl model.TimeControl) error {
+	// Only games known to the registry can be chosen
+	if _, exists := games.Lookup(gameType); !exists {
+		return fmt.Errorf("unsupported game type: %s", gameType)
+	}
+	if err := timeControl.Validate(); err != nil {
 		return err
 	}
 
-	if oppositePlayer.Conn != nil {
-		messageData := map[string]interface{}{
-			"player_id":   player.User.ID,
-			"player_name": player.User.Name,
-			"game_type":   gameType,
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, player); err != nil {
+			return err
 		}
 
-		err := oppositePlayer.Conn.WriteJSON(map[string]interface{}{
+		// Record the player's game choice
+		room.GameSelection.PlayerChoices[player.User.ID] = gameType
+		room.GameSelection.TimeControls[player.User.ID] = timeControl
+
+		// Notify the opposite player about the game choice
+		oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
+		if err != nil {
+			return err
+		}
+
+		messageData := map[string]interface{}{
+			"player_id":    player.User.ID,
+			"player_name":  player.User.Name,
+			"game_type":    gameType,
+			"time_control": timeControl,
+		}
+		sendTo(room, oppositePlayer.User.ID, map[string]interface{}{
 			"type":    model.MessageTypeGameChosen,
 			"message": "Your opponent has chosen a game.",
 			"data":    messageData,
 		})
-		if err != nil {
-			return err
-		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
 // GetGameSelectionState returns the current game selection state for a room
 func (s *RoomService) GetGameSelectionState(ctx context.Context, roomID string) (*model.GameSelectionState, error) {
-	room, err := s.roomRepo.GetRoomByID(ctx, roomID)
+	var state model.GameSelectionState
+	err := s.withRoom(ctx, roomID, func(room *model.Room) error {
+		state.PlayerChoices = make(map[string]model.GameType, len(room.GameSelection.PlayerChoices))
+		for playerID, gameType := range room.GameSelection.PlayerChoices {
+			state.PlayerChoices[playerID] = gameType
+		}
+		state.TimeControls = make(map[string]model.TimeControl, len(room.GameSelection.TimeControls))
+		for playerID, timeControl := range room.GameSelection.TimeControls {
+			state.TimeControls[playerID] = timeControl
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	return &room.GameSelection, nil
+	return &state, nil
 }
 
 // HandleGameAccepted handles when a player accepts a game
-func (s *RoomService) HandleGameAccepted(ctx context.Context, room *model.Room, player model.Player, gameType model.GameType) error {
-
-	// check if the opposite player has choose this game
-	oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
-	if err != nil {
-		return err
-	}
+func (s *RoomService) HandleGameAccepted(ctx context.Context, roomID string, player model.Player, gameType model.GameType) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, player); err != nil {
+			return err
+		}
+		// a new game is only started between rounds
+		if room.Status == model.RoomStatusGameStarted {
+			return ErrorRoundInProgress
+		}
 
-	if room.GameSelection.PlayerChoices[oppositePlayer.User.ID] != gameType {
-		return errors.New("opposite player has not chosen this game")
-	}
+		// check if the opposite player has choose this game
+		oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
+		if err != nil {
+			return err
+		}
 
-	// Record the player's game acceptance
-	room.GameSelection.PlayerChoices[player.User.ID] = gameType
+		if room.GameSelection.PlayerChoices[oppositePlayer.User.ID] != gameType {
+			return errors.New("opposite player has not chosen this game")
+		}
 
-	// Create a new game instance based on gameType
-	game, err := games.CreateGameFromName(string(gameType))
-	if err != nil {
-		return fmt.Errorf("failed to create game: %v", err)
-	}
+		// Record the player's game acceptance, the game is played with the time control it was proposed with
+		room.GameSelection.PlayerChoices[player.User.ID] = gameType
+		room.TimeControl = room.GameSelection.TimeControls[oppositePlayer.User.ID]
+		room.GameSelection.TimeControls[player.User.ID] = room.TimeControl
 
-	// Set players on the game
-	if tttGame, ok := game.(*tictactoe.TicTacToe); ok {
-		// Create a players map without connections (game doesn't need connections)
-		gamePlayers := make(map[string]model.Player)
-		for playerID, p := range room.Players {
-			gamePlayers[playerID] = model.Player{
-				User: p.User,
-				Conn: nil, // Game doesn't need connections
-			}
+		// Create a new game instance based on gameType
+		game, err := games.CreateGameFromName(string(gameType))
+		if err != nil {
+			return fmt.Errorf("failed to create game: %v", err)
 		}
-		tttGame.SetPlayers(gamePlayers)
-	}
-
-	// Start the game
-	if err := game.Start(); err != nil {
-		return fmt.Errorf("failed to start game: %v", err)
-	}
 
-	// Update the room with the new game
-	room.Game = game
-	room.Status = model.RoomStatusGameStarted
+		// the result of a round of another game says nothing about who should start this one
+		room.Seating.LastWinnerID = ""
 
-	// Update room in repository
-	if err := s.UpdateRoom(ctx, *room); err != nil {
-		return fmt.Errorf("failed to update room: %v", err)
-	}
+		// Seat the players and start the game, every registered game takes its players through the same API
+		room.Game = game
+		if err := s.startRound(room); err != nil {
+			return err
+		}
 
-	// Get room response with game state
-	roomResponse := room.GetRoomResponse()
+		// Get room response with game state
+		roomResponse := room.GetRoomResponse()
 
-	// notify the opposite player about the game acceptance
-	if oppositePlayer.Conn != nil {
-		err = oppositePlayer.Conn.WriteJSON(map[string]interface{}{
+		// notify the opposite player about the game acceptance
+		sendTo(room, oppositePlayer.User.ID, map[string]interface{}{
 			"type":    model.MessageTypeGameAccepted,
 			"message": "Your opponent has accepted the game.",
 		})
-		if err != nil {
-			return err
-		}
-	}
 
-	// Send start_game message to both players when game is accepted with room details
-	if player.Conn != nil {
-		err = player.Conn.WriteJSON(map[string]interface{}{
+		// Send start_game message to both players when game is accepted with room details
+		broadcast(room, map[string]interface{}{
 			"type":      model.MessageTypeStartGame,
 			"game_type": gameType,
 			"room_id":   room.ID,
 			"data":      roomResponse,
 		})
+
+		return nil
+	})
+}
+
+// HandleGameRejected handles when a player rejects a game
+func (s *RoomService) HandleGameRejected(ctx context.Context, roomID string, player model.Player, gameType model.GameType) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, player); err != nil {
+			return err
+		}
+
+		// check if the opposite player has choose this game
+		oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
 		if err != nil {
 			return err
 		}
-	}
 
-	if oppositePlayer.Conn != nil {
-		err = oppositePlayer.Conn.WriteJSON(map[string]interface{}{
-			"type":      model.MessageTypeStartGame,
-			"game_type": gameType,
-			"room_id":   room.ID,
-			"data":      roomResponse,
+		// notify the opposite player about the game rejection
+		sendTo(room, oppositePlayer.User.ID, map[string]interface{}{
+			"type":    model.MessageTypeGameRejected,
+			"message": "Your opponent has rejected the game.",
 		})
-		if err != nil {
+
+		return nil
+	})
+}
+
+// HandleGameMove applies a player's move and broadcasts the new state to both players
+func (s *RoomService) HandleGameMove(ctx context.Context, roomID string, player model.Player, move any) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, player); err != nil {
 			return err
 		}
+		return s.applyMove(room, player.User.ID, move)
+	})
+}
+
+// applyMove makes the move for playerID, a player or a bot, and broadcasts the new state
+func (s *RoomService) applyMove(room *model.Room, playerID string, move any) error {
+	if room.Game == nil {
+		return errors.New("game not started")
+	}
+	if room.Status != model.RoomStatusGameStarted {
+		return errors.New("game is not in progress")
 	}
 
-	return nil
-}
+	// a move that arrives after the player's time ran out loses the round instead
+	if timeRanOut(room, playerID) {
+		s.timeoutRound(room, playerID)
+		return ErrorTimeUp
+	}
 
-// HandleGameRejected handles when a player rejects a game
-func (s *RoomService) HandleGameRejected(ctx context.Context, room *model.Room, player model.Player, gameType model.GameType) error {
-	// check if the opposite player has choose this game
-	oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
-	if err != nil {
+	// Make the move
+	if err := room.Game.MakeMove(playerID, move); err != nil {
 		return err
 	}
-
-	// notify the opposite player about the game rejection
-	if oppositePlayer.Conn != nil {
-		err = oppositePlayer.Conn.WriteJSON(map[string]interface{}{
-			"type":    model.MessageTypeGameRejected,
-			"message": "Your opponent has rejected the game.",
-		})
+	stopClock(room, true)
+	move = playedMove(room.Game)
+	room.RoundMoves = append(room.RoundMoves, model.MatchMove{PlayerID: playerID, Move: move, PlayedAt: time.Now()})
+	s.publish(room, model.RoomEventTypeMoveMade, playerID, move)
+	// a move withdraws the offers waiting for an answer
+	room.DrawOfferedBy = ""
+	room.UndoRequestedBy = ""
+
+	// Update room status if game is over
+	if room.Game.IsGameOver() {
+		result := model.RoundResult{Reason: model.EndReasonCompleted}
+		if winner := room.Game.GetWinner(); winner != nil {
+			result.WinnerID = winner.User.ID
+		}
+		s.endRound(room, result)
+	} else {
+		s.startClock(room)
 	}
 
+	// Broadcast the move to both players with the updated game state
+	broadcast(room, map[string]interface{}{
+		"type":    model.MessageTypeMoveMade,
+		"message": "Move made",
+		"data":    room.GetRoomResponse(),
+	})
+	s.scheduleBotTurn(room)
 	return nil
 }
 
-func (s *RoomService) HandleReplayGame(ctx context.Context, room *model.Room, player model.Player, msg map[string]interface{}) error {
-	// check if the opposite player has choose this game
-	oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
-	if err != nil {
-		return err
+// playedMove returns the move the game just made as the game stores it, never the message the client sent,
+// so extra fields in the message do not end up in the match history. Games that keep no history record no move
+func playedMove(game model.Game) any {
+	undoer, ok := game.(model.Undoer)
+	if !ok {
+		return nil
+	}
+	history := undoer.History()
+	if len(history) == 0 {
+		return nil
 	}
+	return history[len(history)-1].Move
+}
+
+func (s *RoomService) HandleReplayGame(ctx context.Context, roomID string, player model.Player, msg map[string]interface{}) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, player); err != nil {
+			return err
+		}
+
+		// check if the opposite player has choose this game
+		oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
+		if err != nil {
+			return err
+		}
+
+		// a replay is only asked for once the round is over, a bot would otherwise restart a running round
+		if room.Status != model.RoomStatusGameOver {
+			return ErrorRoundNotOver
+		}
 
-	// notify the opposite player about the replay game
-	if oppositePlayer.Conn != nil {
-		err = oppositePlayer.Conn.WriteJSON(map[string]interface{}{
+		// a bot always takes a replay
+		if oppositePlayer.Bot != nil {
+			sendTo(room, player.User.ID, map[string]interface{}{
+				"type":    model.MessageTypeReplayAccepted,
+				"message": "Your opponent has accepted the replay.",
+			})
+			return s.replayRound(room)
+		}
+
+		room.ReplayRequestedBy = player.User.ID
+
+		// notify the opposite player about the replay game
+		sendTo(room, oppositePlayer.User.ID, map[string]interface{}{
 			"type":    model.MessageTypeReplayGame,
 			"message": "Your opponent has requested a replay.",
 		})
-	}
 
-	return nil
+		return nil
+	})
 }
 
-func (s *RoomService) HandleReplayAccepted(ctx context.Context, room *model.Room, player model.Player, msg map[string]interface{}) error {
-	// check if the opposite player has choose this game
-	oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
-	if err != nil {
-		return err
-	}
+func (s *RoomService) HandleReplayAccepted(ctx context.Context, roomID string, player model.Player, msg map[string]interface{}) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, player); err != nil {
+			return err
+		}
+		if room.Game == nil {
+			return errors.New("no game to replay")
+		}
+		if room.Status != model.RoomStatusGameOver {
+			return ErrorRoundNotOver
+		}
+
+		// check if the opposite player has choose this game
+		oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
+		if err != nil {
+			return err
+		}
+		// only a replay the opponent asked for can be accepted
+		if room.ReplayRequestedBy != oppositePlayer.User.ID {
+			return ErrorNoReplayRequest
+		}
 
-	// notify the opposite player about the replay accepted
-	if oppositePlayer.Conn != nil {
-		err = oppositePlayer.Conn.WriteJSON(map[string]interface{}{
+		// notify the opposite player about the replay accepted
+		sendTo(room, oppositePlayer.User.ID, map[string]interface{}{
 			"type":    model.MessageTypeReplayAccepted,
 			"message": "Your opponent has accepted the replay.",
 		})
-	}
 
-	// reset the game state
-	room.Game.ResetState()
+		return s.replayRound(room)
+	})
+}
 
-	s.StartGame(ctx, room.ID)
+// replayRound resets the game state and starts the next round with freshly assigned seats
+func (s *RoomService) replayRound(room *model.Room) error {
+	recordRoundResult(room)
+	if err := room.Game.ResetState(); err != nil {
+		return err
+	}
+	if err := s.startRound(room); err != nil {
+		return fmt.Errorf("error starting game")
+	}
 
+	// both players get the new seats with the fresh game state
+	broadcast(room, map[string]interface{}{
+		"type":      model.MessageTypeStartGame,
+		"game_type": room.Game.GetType(),
+		"room_id":   room.ID,
+		"data":      room.GetRoomResponse(),
+	})
 	return nil
 }
 
-func (s *RoomService) HandleReplayRejected(ctx context.Context, room *model.Room, player model.Player, msg map[string]interface{}) error {
-	// check if the opposite player has choose this game
-	oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
-	if err != nil {
-		return err
-	}
+func (s *RoomService) HandleReplayRejected(ctx context.Context, roomID string, player model.Player, msg map[string]interface{}) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, player); err != nil {
+			return err
+		}
+
+		// check if the opposite player has choose this game
+		oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
+		if err != nil {
+			return err
+		}
 
-	// notify the opposite player about the replay rejected
-	if oppositePlayer.Conn != nil {
-		err = oppositePlayer.Conn.WriteJSON(map[string]interface{}{
+		if room.ReplayRequestedBy != oppositePlayer.User.ID {
+			return ErrorNoReplayRequest
+		}
+		room.ReplayRequestedBy = ""
+
+		// notify the opposite player about the replay rejected
+		sendTo(room, oppositePlayer.User.ID, map[string]interface{}{
 			"type":    model.MessageTypeReplayRejected,
 			"message": "Your opponent has rejected the replay.",
 		})
+
+		return nil
+	})
+}
+
+// HandleSetSeatPolicy lets the room host change how seats are assigned, the policy applies from the next round
+func (s *RoomService) HandleSetSeatPolicy(ctx context.Context, roomID string, player model.Player, policy model.SeatPolicy, firstPlayerID string) error {
+	if !policy.IsValid() {
+		return fmt.Errorf("unknown seat policy: %s", policy)
 	}
 
-	return nil
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if room.Seating.HostID != player.User.ID {
+			return errors.New("only the room host can change the seat policy")
+		}
+		if policy == model.SeatPolicyHostChooses {
+			if firstPlayerID == "" {
+				firstPlayerID = player.User.ID
+			}
+			if _, exists := room.Players[firstPlayerID]; !exists {
+				return errors.New("first player is not in the room")
+			}
+		} else {
+			firstPlayerID = ""
+		}
+
+		room.Seating.Policy = policy
+		room.Seating.FirstPlayerID = firstPlayerID
+
+		// let both players know the new seating rules
+		broadcast(room, map[string]interface{}{
+			"type":    model.MessageTypeSeatPolicySet,
+			"message": "Seat policy updated.",
+			"data":    room.GetRoomResponse(),
+		})
+		return nil
+	})
 }
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+)
+
+// newTestRoomService returns a room service on in-memory repositories, it is closed when the test ends
+func newTestRoomService(t *testing.T) (*RoomService, repository.RatingRepository) {
+	s, ratingRepo, _ := newTestRoomServiceWithMatches(t)
+	return s, ratingRepo
+}
+
+// newTestRoomServiceWithMatches is newTestRoomService that also returns the match repository
+func newTestRoomServiceWithMatches(t *testing.T) (*RoomService, repository.RatingRepository, repository.MatchRepository) {
+	t.Helper()
+	ratingRepo := repository.NewRatingRepository()
+	matchRepo := repository.NewMatchRepository()
+	s := NewRoomService(
+		repository.NewRoomRepository(),
+		repository.NewQueueRepository(),
+		repository.NewUserRepository(),
+		NewRatingService(ratingRepo),
+		NewMatchService(matchRepo),
+		NewReportService(repository.NewReportRepository()),
+		nil,
+		RoomConfig{BotOfferAfter: -1},
+	)
+	t.Cleanup(s.Close)
+	return s, ratingRepo, matchRepo
+}
+
+func testPlayer(id string) model.Player {
+	return model.Player{User: model.User{ID: id, Name: id}}
+}
+
+// startTicTacToe puts alice and bob in a new room and starts an untimed round of tic tac toe
+func startTicTacToe(t *testing.T, s *RoomService) (string, model.Player, model.Player) {
+	t.Helper()
+	return startTimedTicTacToe(t, s, model.TimeControl{})
+}
+
+// startTimedTicTacToe is startTicTacToe with alice proposing the time control
+func startTimedTicTacToe(t *testing.T, s *RoomService, timeControl model.TimeControl) (string, model.Player, model.Player) {
+	t.Helper()
+	ctx := context.Background()
+	alice, bob := testPlayer("alice"), testPlayer("bob")
+
+	room, err := s.CreateRoom(ctx, alice)
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+	if _, err := s.JoinRoom(ctx, room.ID, bob); err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	if err := s.HandleGameChosen(ctx, room.ID, alice, tictactoe.GameType, timeControl); err != nil {
+		t.Fatalf("HandleGameChosen: %v", err)
+	}
+	if err := s.HandleGameAccepted(ctx, room.ID, bob, tictactoe.GameType); err != nil {
+		t.Fatalf("HandleGameAccepted: %v", err)
+	}
+	return room.ID, alice, bob
+}
+
+// playToWin plays a tic tac toe round to a win for the player in the first seat and returns the winner's ID
+func playToWin(t *testing.T, s *RoomService, roomID string) string {
+	t.Helper()
+	ctx := context.Background()
+	first, second := seatedPlayers(s, roomID)
+	moves := []struct {
+		playerID string
+		move     tictactoe.Move
+	}{
+		{first, tictactoe.Move{Row: 0, Col: 0}},
+		{second, tictactoe.Move{Row: 1, Col: 0}},
+		{first, tictactoe.Move{Row: 0, Col: 1}},
+		{second, tictactoe.Move{Row: 1, Col: 1}},
+		{first, tictactoe.Move{Row: 0, Col: 2}},
+	}
+	for _, m := range moves {
+		if err := s.HandleGameMove(ctx, roomID, testPlayer(m.playerID), m.move); err != nil {
+			t.Fatalf("move %+v by %s: %v", m.move, m.playerID, err)
+		}
+	}
+	return first
+}
+
+// seatedPlayers returns the IDs of the players in the first and second seat of the room's round
+func seatedPlayers(s *RoomService, roomID string) (string, string) {
+	var first, second string
+	s.withRoom(context.Background(), roomID, func(room *model.Room) error {
+		first, second = room.Seating.Order[0], room.Seating.Order[1]
+		return nil
+	})
+	return first, second
+}
+
+func roomStatus(t *testing.T, s *RoomService, roomID string) model.RoomResponse {
+	t.Helper()
+	room, err := s.GetRoomResponse(context.Background(), roomID)
+	if err != nil {
+		t.Fatalf("GetRoomResponse: %v", err)
+	}
+	return room
+}
+
+func TestLeaveRoomMidGameForfeits(t *testing.T) {
+	s, ratingRepo := newTestRoomService(t)
+	ctx := context.Background()
+	roomID, alice, bob := startTicTacToe(t, s)
+
+	if err := s.LeaveRoom(ctx, roomID, alice.User.ID); err != nil {
+		t.Fatalf("LeaveRoom: %v", err)
+	}
+	if _, err := s.GetRoomResponse(ctx, roomID); !errors.Is(err, repository.ErrRoomNotFound) {
+		t.Fatalf("room still open after leaving: %v", err)
+	}
+
+	// Close waits for the round worker, so the forfeit has been rated
+	s.Close()
+	rating, _ := ratingRepo.GetRating(ctx, bob.User.ID, tictactoe.GameType)
+	if rating.Wins != 1 {
+		t.Errorf("bob has %d wins after alice left mid-game, want 1", rating.Wins)
+	}
+	rating, _ = ratingRepo.GetRating(ctx, alice.User.ID, tictactoe.GameType)
+	if rating.Losses != 1 {
+		t.Errorf("alice has %d losses after leaving mid-game, want 1", rating.Losses)
+	}
+}
+
+func TestGameAcceptedRejectedDuringRound(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	roomID, _, bob := startTicTacToe(t, s)
+
+	err := s.HandleGameAccepted(ctx, roomID, bob, tictactoe.GameType)
+	if !errors.Is(err, ErrorRoundInProgress) {
+		t.Fatalf("accepting a game mid-round returned %v, want %v", err, ErrorRoundInProgress)
+	}
+}
+
+func TestReplayNeedsFinishedRoundAndRequest(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	roomID, alice, bob := startTicTacToe(t, s)
+
+	if err := s.HandleReplayGame(ctx, roomID, alice, nil); !errors.Is(err, ErrorRoundNotOver) {
+		t.Fatalf("replay request mid-round returned %v, want %v", err, ErrorRoundNotOver)
+	}
+	if err := s.HandleReplayAccepted(ctx, roomID, bob, nil); !errors.Is(err, ErrorRoundNotOver) {
+		t.Fatalf("replay accepted mid-round returned %v, want %v", err, ErrorRoundNotOver)
+	}
+
+	playToWin(t, s, roomID)
+	if err := s.HandleReplayAccepted(ctx, roomID, bob, nil); !errors.Is(err, ErrorNoReplayRequest) {
+		t.Fatalf("replay accepted without a request returned %v, want %v", err, ErrorNoReplayRequest)
+	}
+	if err := s.HandleReplayGame(ctx, roomID, alice, nil); err != nil {
+		t.Fatalf("HandleReplayGame: %v", err)
+	}
+	// the player who asked cannot accept their own request
+	if err := s.HandleReplayAccepted(ctx, roomID, alice, nil); !errors.Is(err, ErrorNoReplayRequest) {
+		t.Fatalf("requester accepting their own replay returned %v, want %v", err, ErrorNoReplayRequest)
+	}
+	if err := s.HandleReplayAccepted(ctx, roomID, bob, nil); err != nil {
+		t.Fatalf("HandleReplayAccepted: %v", err)
+	}
+	room := roomStatus(t, s, roomID)
+	if room.Status != model.RoomStatusGameStarted || room.ReplayRequestedBy != "" {
+		t.Errorf("after the replay status = %s, replay requested by %q", room.Status, room.ReplayRequestedBy)
+	}
+}
+
+func TestBotReplayNeedsFinishedRound(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	alice := testPlayer("alice")
+	room, err := s.CreateBotRoom(ctx, alice, tictactoe.GameType, "")
+	if err != nil {
+		t.Fatalf("CreateBotRoom: %v", err)
+	}
+
+	if err := s.HandleReplayGame(ctx, room.ID, alice, nil); !errors.Is(err, ErrorRoundNotOver) {
+		t.Fatalf("replay against a bot mid-round returned %v, want %v", err, ErrorRoundNotOver)
+	}
+	if after := roomStatus(t, s, room.ID); after.Seating.Round != room.Seating.Round {
+		t.Errorf("replay mid-round restarted the round, seating round %d -> %d", room.Seating.Round, after.Seating.Round)
+	}
+}
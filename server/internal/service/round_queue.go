@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+const (
+	// roundRecordAttempts is how many times a finished round is tried before it is given up on
+	roundRecordAttempts = 5
+	// roundRecordBackoff is the wait before the first retry, it doubles with every attempt
+	roundRecordBackoff = 100 * time.Millisecond
+)
+
+// finishedRound is what a round leaves behind once it is over
+type finishedRound struct {
+	// match is the record of the round for the match history
+	match     model.Match
+	roomID    string
+	gameType  model.GameType
+	playerIDs []string
+	winnerID  string
+	// practice rounds against a bot leave ratings alone
+	practice bool
+}
+
+// roundQueue hands finished rounds from the room actors to the worker that records them.
+// Unlike the event bus it never drops a round and never blocks the room, it grows until the worker catches up
+type roundQueue struct {
+	mu      sync.Mutex
+	pending []finishedRound
+	// wake tells the worker there are rounds pending
+	wake chan struct{}
+}
+
+func newRoundQueue() *roundQueue {
+	return &roundQueue{wake: make(chan struct{}, 1)}
+}
+
+func (q *roundQueue) push(round finishedRound) {
+	q.mu.Lock()
+	q.pending = append(q.pending, round)
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// take removes and returns every pending round, oldest first
+func (q *roundQueue) take() []finishedRound {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	rounds := q.pending
+	q.pending = nil
+	return rounds
+}
+
+// queueFinishedRound hands the room's round that just ended and its match record to the round worker
+func (s *RoomService) queueFinishedRound(room *model.Room, match model.Match) {
+	round := finishedRound{
+		match:    match,
+		roomID:   room.ID,
+		gameType: room.Game.GetType(),
+		practice: room.IsPractice(),
+	}
+	if room.Result != nil {
+		round.winnerID = room.Result.WinnerID
+	}
+	for id := range room.Players {
+		round.playerIDs = append(round.playerIDs, id)
+	}
+	s.rounds.push(round)
+}
+
+// recordRounds stores and rates the finished rounds one at a time, so two rounds of the same player never
+// update their rating at once. Rounds still pending at shutdown are recorded before it returns
+func (s *RoomService) recordRounds(ctx context.Context) {
+	defer close(s.roundsDone)
+	for {
+		for _, round := range s.rounds.take() {
+			s.recordRound(round)
+		}
+		select {
+		case <-s.rounds.wake:
+		case <-ctx.Done():
+			for _, round := range s.rounds.take() {
+				s.recordRound(round)
+			}
+			return
+		}
+	}
+}
+
+// recordRound saves the match record of a finished round and updates the ratings, practice rounds
+// are kept in the match history but leave ratings alone
+func (s *RoomService) recordRound(round finishedRound) {
+	if s.matchService != nil {
+		if err := retry(func() error {
+			return s.matchService.SaveMatch(context.Background(), round.match)
+		}); err != nil {
+			fmt.Printf("Failed to save match of room %s: %v\n", round.roomID, err)
+		}
+	}
+
+	if round.practice || len(round.playerIDs) != 2 || s.ratingService == nil {
+		return
+	}
+	if err := retry(func() error {
+		return s.ratingService.RecordResult(context.Background(), round.gameType, round.playerIDs[0], round.playerIDs[1], round.winnerID)
+	}); err != nil {
+		fmt.Printf("Failed to update ratings for room %s: %v\n", round.roomID, err)
+	}
+}
+
+// retry calls fn until it succeeds, waiting a growing backoff between attempts, and
+// returns the last error once roundRecordAttempts have failed
+func retry(fn func() error) error {
+	backoff := roundRecordBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt == roundRecordAttempts {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
+	"github.com/kaviraj-j/duoplay/internal/model"
+	"github.com/kaviraj-j/duoplay/internal/repository"
+)
+
+func TestFinishedRoundsAreSavedWithoutTheBus(t *testing.T) {
+	// the test service has no event bus, so the match can only arrive through the round queue
+	s, _, matchRepo := newTestRoomServiceWithMatches(t)
+	ctx := context.Background()
+	roomID, _, _ := startTicTacToe(t, s)
+	winnerID := playToWin(t, s, roomID)
+	s.Close()
+
+	matches, total, err := matchRepo.ListByUser(ctx, winnerID, "", 10, 0)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if total != 1 || len(matches) != 1 {
+		t.Fatalf("got %d matches (total %d), want 1", len(matches), total)
+	}
+	match := matches[0]
+	if match.RoomID != roomID || match.WinnerID != winnerID || match.EndReason != model.EndReasonCompleted {
+		t.Errorf("match = %+v", match)
+	}
+	if len(match.Moves) != 5 || len(match.Players) != 2 {
+		t.Errorf("match has %d moves and %d players, want 5 and 2", len(match.Moves), len(match.Players))
+	}
+}
+
+func TestMatchRecordKeepsTheGamesMove(t *testing.T) {
+	s, _, matchRepo := newTestRoomServiceWithMatches(t)
+	ctx := context.Background()
+	roomID, alice, bob := startTicTacToe(t, s)
+
+	var first string
+	s.withRoom(ctx, roomID, func(room *model.Room) error {
+		first = room.Seating.Order[0]
+		return nil
+	})
+	second := alice
+	if first == alice.User.ID {
+		second = bob
+	}
+	// the client's message carries fields the game does not know about
+	move := map[string]interface{}{"row": 2, "col": 2, "note": strings.Repeat("x", 1024)}
+	if err := s.HandleGameMove(ctx, roomID, testPlayer(first), move); err != nil {
+		t.Fatalf("HandleGameMove: %v", err)
+	}
+	if err := s.LeaveRoom(ctx, roomID, second.User.ID); err != nil {
+		t.Fatalf("LeaveRoom: %v", err)
+	}
+	s.Close()
+
+	matches, _, err := matchRepo.ListByUser(ctx, first, "", 10, 0)
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("ListByUser returned %d matches: %v", len(matches), err)
+	}
+	moves := matches[0].Moves
+	if len(moves) != 1 {
+		t.Fatalf("match has %d moves, want 1", len(moves))
+	}
+	if want := (tictactoe.Move{Row: 2, Col: 2}); moves[0].Move != want {
+		t.Errorf("stored move = %#v, want %#v", moves[0].Move, want)
+	}
+}
+
+// flakyMatchRepository fails as many saves as failures before it lets them through
+type flakyMatchRepository struct {
+	repository.MatchRepository
+	mu       sync.Mutex
+	failures int
+	attempts int
+}
+
+func (r *flakyMatchRepository) Save(ctx context.Context, match model.Match) error {
+	r.mu.Lock()
+	r.attempts++
+	fail := r.attempts <= r.failures
+	r.mu.Unlock()
+	if fail {
+		return errors.New("database is locked")
+	}
+	return r.MatchRepository.Save(ctx, match)
+}
+
+func TestRecordRoundRetriesFailedSaves(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	repo := &flakyMatchRepository{MatchRepository: repository.NewMatchRepository(), failures: 2}
+	s.matchService = NewMatchService(repo)
+
+	s.recordRound(finishedRound{
+		match:     model.Match{ID: "m1", RoomID: "r1", GameType: tictactoe.GameType},
+		roomID:    "r1",
+		gameType:  tictactoe.GameType,
+		playerIDs: []string{"alice", "bot"},
+		practice:  true,
+	})
+	if repo.attempts != 3 {
+		t.Errorf("save was attempted %d times, want 3", repo.attempts)
+	}
+	if _, err := repo.FindByID(context.Background(), "m1"); err != nil {
+		t.Errorf("match not saved after retries: %v", err)
+	}
+}
+
+func TestRoundQueueKeepsOrder(t *testing.T) {
+	q := newRoundQueue()
+	for _, id := range []string{"a", "b", "c"} {
+		q.push(finishedRound{roomID: id})
+	}
+	rounds := q.take()
+	if len(rounds) != 3 || rounds[0].roomID != "a" || rounds[2].roomID != "c" {
+		t.Errorf("take returned %+v", rounds)
+	}
+	if rest := q.take(); len(rest) != 0 {
+		t.Errorf("second take returned %d rounds", len(rest))
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// assignSeats starts a new seating round for the room according to its seat policy
+// and returns the players in seat order, the first player moves first
+func assignSeats(room *model.Room) ([]model.Player, error) {
+	if len(room.Players) < 2 {
+		return nil, errors.New("not enough players to assign seats")
+	}
+
+	// sorted IDs give the random draw the same input for the same seed
+	playerIDs := make([]string, 0, len(room.Players))
+	for id := range room.Players {
+		playerIDs = append(playerIDs, id)
+	}
+	sort.Strings(playerIDs)
+
+	seating := &room.Seating
+	seating.Round++
+	// a previous order is only usable when it seated the same players
+	hasPreviousOrder := seating.Round > 1 && sameMembers(seating.Order, playerIDs)
+
+	var order []string
+	switch seating.Policy {
+	case model.SeatPolicyHostChooses:
+		firstPlayerID := seating.FirstPlayerID
+		if _, exists := room.Players[firstPlayerID]; !exists {
+			firstPlayerID = seating.HostID
+		}
+		if _, exists := room.Players[firstPlayerID]; !exists {
+			firstPlayerID = playerIDs[0]
+		}
+		seating.Seed = 0
+		order = moveToFront(playerIDs, firstPlayerID)
+	case model.SeatPolicyAlternate:
+		if hasPreviousOrder {
+			seating.Seed = 0
+			order = rotate(seating.Order)
+		} else {
+			order = drawSeatOrder(seating, playerIDs)
+		}
+	case model.SeatPolicyLoserStarts:
+		if hasPreviousOrder {
+			seating.Seed = 0
+			if seating.LastWinnerID == "" {
+				// nobody lost a draw, so the seats alternate
+				order = rotate(seating.Order)
+			} else {
+				order = moveToBack(seating.Order, seating.LastWinnerID)
+			}
+		} else {
+			order = drawSeatOrder(seating, playerIDs)
+		}
+	default:
+		order = drawSeatOrder(seating, playerIDs)
+	}
+	seating.Order = order
+
+	players := make([]model.Player, 0, len(order))
+	for _, id := range order {
+		players = append(players, room.Players[id])
+	}
+	return players, nil
+}
+
+// recordRoundResult keeps the winner of the finished round for the next seat assignment
+func recordRoundResult(room *model.Room) {
+	room.Seating.LastWinnerID = ""
+	if room.Result != nil {
+		// the result also covers rounds that did not end on the board, such as a forfeit
+		room.Seating.LastWinnerID = room.Result.WinnerID
+		return
+	}
+	if room.Game == nil {
+		return
+	}
+	if winner := room.Game.GetWinner(); winner != nil {
+		room.Seating.LastWinnerID = winner.User.ID
+	}
+}
+
+// drawSeatOrder shuffles the players with a fresh seed and records the seed on the room
+func drawSeatOrder(seating *model.SeatingState, playerIDs []string) []string {
+	seating.Seed = time.Now().UnixNano()
+	order := append([]string(nil), playerIDs...)
+	rng := rand.New(rand.NewSource(seating.Seed))
+	rng.Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+	return order
+}
+
+// rotate moves the first seat to the back so everyone moves up one seat
+func rotate(order []string) []string {
+	rotated := append([]string(nil), order[1:]...)
+	return append(rotated, order[0])
+}
+
+func moveToFront(order []string, playerID string) []string {
+	moved := []string{playerID}
+	for _, id := range order {
+		if id != playerID {
+			moved = append(moved, id)
+		}
+	}
+	return moved
+}
+
+func moveToBack(order []string, playerID string) []string {
+	moved := make([]string, 0, len(order))
+	for _, id := range order {
+		if id != playerID {
+			moved = append(moved, id)
+		}
+	}
+	return append(moved, playerID)
+}
+
+func sameMembers(order []string, sortedIDs []string) bool {
+	if len(order) != len(sortedIDs) {
+		return false
+	}
+	sorted := append([]string(nil), order...)
+	sort.Strings(sorted)
+	for i := range sorted {
+		if sorted[i] != sortedIDs[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/kaviraj-j/duoplay/internal/games/connectfour"
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// newSeatingRoom returns a room with the players in it and the seat policy set, host is the first player
+func newSeatingRoom(policy model.SeatPolicy, playerIDs ...string) *model.Room {
+	room := model.NewRoom()
+	for _, id := range playerIDs {
+		room.Players[id] = model.Player{User: model.User{ID: id, Name: id}}
+	}
+	room.Seating.Policy = policy
+	room.Seating.HostID = playerIDs[0]
+	return &room
+}
+
+func seatOrder(t *testing.T, room *model.Room) []string {
+	t.Helper()
+	players, err := assignSeats(room)
+	if err != nil {
+		t.Fatalf("assignSeats: %v", err)
+	}
+	order := make([]string, 0, len(players))
+	for _, p := range players {
+		order = append(order, p.User.ID)
+	}
+	if !reflect.DeepEqual(order, room.Seating.Order) {
+		t.Fatalf("returned order %v differs from recorded order %v", order, room.Seating.Order)
+	}
+	return order
+}
+
+func TestAssignSeatsRandomIsReproducibleFromSeed(t *testing.T) {
+	room := newSeatingRoom(model.SeatPolicyRandom, "alice", "bob")
+	order := seatOrder(t, room)
+
+	// the recorded seed shuffles the sorted player IDs into the same order
+	ids := []string{"alice", "bob"}
+	sort.Strings(ids)
+	rng := rand.New(rand.NewSource(room.Seating.Seed))
+	rng.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	if !reflect.DeepEqual(order, ids) {
+		t.Errorf("order %v, seed %d replays to %v", order, room.Seating.Seed, ids)
+	}
+	if room.Seating.Round != 1 {
+		t.Errorf("round = %d, want 1", room.Seating.Round)
+	}
+}
+
+func TestAssignSeatsAlternateSwapsEveryRound(t *testing.T) {
+	room := newSeatingRoom(model.SeatPolicyAlternate, "alice", "bob")
+	first := seatOrder(t, room)
+	for round := 2; round <= 4; round++ {
+		order := seatOrder(t, room)
+		want := first
+		if round%2 == 0 {
+			want = []string{first[1], first[0]}
+		}
+		if !reflect.DeepEqual(order, want) {
+			t.Errorf("round %d order = %v, want %v", round, order, want)
+		}
+		if room.Seating.Seed != 0 {
+			t.Errorf("round %d recorded seed %d for a rotation", round, room.Seating.Seed)
+		}
+	}
+}
+
+func TestAssignSeatsLoserStarts(t *testing.T) {
+	tests := []struct {
+		name   string
+		winner string
+		// wantFirst is the first seat of the second round, "" when the seats swap
+		wantFirst string
+	}{
+		{name: "alice won", winner: "alice", wantFirst: "bob"},
+		{name: "bob won", winner: "bob", wantFirst: "alice"},
+		{name: "draw", winner: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			room := newSeatingRoom(model.SeatPolicyLoserStarts, "alice", "bob")
+			first := seatOrder(t, room)
+
+			room.Result = &model.RoundResult{WinnerID: tt.winner, Reason: model.EndReasonCompleted}
+			recordRoundResult(room)
+			order := seatOrder(t, room)
+
+			wantFirst := tt.wantFirst
+			if wantFirst == "" {
+				wantFirst = first[1]
+			}
+			if order[0] != wantFirst {
+				t.Errorf("second round order = %v, want %s first", order, wantFirst)
+			}
+		})
+	}
+}
+
+func TestAssignSeatsHostChooses(t *testing.T) {
+	tests := []struct {
+		name      string
+		pick      string
+		wantFirst string
+	}{
+		{name: "host picks the guest", pick: "bob", wantFirst: "bob"},
+		{name: "host picks themselves", pick: "alice", wantFirst: "alice"},
+		{name: "no pick seats the host first", pick: "", wantFirst: "alice"},
+		{name: "pick of someone not in the room seats the host first", pick: "carol", wantFirst: "alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			room := newSeatingRoom(model.SeatPolicyHostChooses, "alice", "bob")
+			room.Seating.FirstPlayerID = tt.pick
+			if order := seatOrder(t, room); order[0] != tt.wantFirst {
+				t.Errorf("order = %v, want %s first", order, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestAssignSeatsNeedsTwoPlayers(t *testing.T) {
+	room := newSeatingRoom(model.SeatPolicyRandom, "alice")
+	if _, err := assignSeats(room); err == nil {
+		t.Fatalf("assignSeats succeeded with one player")
+	}
+}
+
+func TestNewGameForgetsTheLastWinner(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	roomID, alice, bob := startTicTacToe(t, s)
+	if err := s.HandleSetSeatPolicy(ctx, roomID, alice, model.SeatPolicyLoserStarts, ""); err != nil {
+		t.Fatalf("HandleSetSeatPolicy: %v", err)
+	}
+
+	// the first seat wins and the replay records them as the last winner, so they sit second
+	winner := playToWin(t, s, roomID)
+	replayer, accepter := alice, bob
+	if err := s.HandleReplayGame(ctx, roomID, replayer, nil); err != nil {
+		t.Fatalf("HandleReplayGame: %v", err)
+	}
+	if err := s.HandleReplayAccepted(ctx, roomID, accepter, nil); err != nil {
+		t.Fatalf("HandleReplayAccepted: %v", err)
+	}
+	first, second := seatedPlayers(s, roomID)
+	if second != winner {
+		t.Fatalf("replay seated %s first, want the loser first", first)
+	}
+	if err := s.HandleResign(ctx, roomID, testPlayer(first)); err != nil {
+		t.Fatalf("HandleResign: %v", err)
+	}
+
+	// a different game is chosen next, the tic tac toe winner must not decide its seats, so they alternate
+	if err := s.HandleGameChosen(ctx, roomID, alice, connectfour.GameType, model.TimeControl{}); err != nil {
+		t.Fatalf("HandleGameChosen: %v", err)
+	}
+	if err := s.HandleGameAccepted(ctx, roomID, bob, connectfour.GameType); err != nil {
+		t.Fatalf("HandleGameAccepted: %v", err)
+	}
+	room := roomStatus(t, s, roomID)
+	if want := []string{second, first}; !reflect.DeepEqual(room.Seating.Order, want) {
+		t.Errorf("seat order for the new game = %v, want %v", room.Seating.Order, want)
+	}
+	if room.Seating.LastWinnerID != "" {
+		t.Errorf("last winner %q carried over to the new game", room.Seating.LastWinnerID)
+	}
+}
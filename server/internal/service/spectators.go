@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// WatchRoom adds the spectator to the room and returns the room, everyone in the room gets the new spectator count
+func (s *RoomService) WatchRoom(ctx context.Context, roomID string, spectator model.Spectator) (model.RoomResponse, error) {
+	var roomResponse model.RoomResponse
+	err := s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if _, exists := room.Players[spectator.User.ID]; exists {
+			return errors.New("players cannot watch their own room")
+		}
+
+		// a second tab replaces the first one
+		count := len(room.Spectators)
+		if previous, exists := room.Spectators[spectator.User.ID]; exists {
+			previous.Conn.Close()
+		} else {
+			count++
+		}
+
+		// the room hears about the spectator before they are added, they get the room itself instead
+		broadcast(room, map[string]interface{}{
+			"type":    model.MessageTypeSpectatorJoined,
+			"message": "A spectator is watching.",
+			"data": map[string]interface{}{
+				"user":            spectator.User,
+				"spectator_count": count,
+			},
+		})
+		if err := s.roomRepo.AddSpectatorToRoom(ctx, room.ID, spectator); err != nil {
+			return err
+		}
+		roomResponse = room.GetRoomResponse()
+		return nil
+	})
+	return roomResponse, err
+}
+
+// RemoveSpectator takes the spectator out of the room when their connection closes,
+// a connection that was already replaced is ignored
+func (s *RoomService) RemoveSpectator(ctx context.Context, roomID string, spectator model.Spectator) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		current, exists := room.Spectators[spectator.User.ID]
+		if !exists || current.Conn != spectator.Conn {
+			return nil
+		}
+		if err := s.roomRepo.RemoveSpectatorFromRoom(ctx, room.ID, spectator.User.ID); err != nil {
+			return err
+		}
+
+		broadcast(room, map[string]interface{}{
+			"type":    model.MessageTypeSpectatorLeft,
+			"message": "A spectator has left.",
+			"data": map[string]interface{}{
+				"user":            spectator.User,
+				"spectator_count": len(room.Spectators),
+			},
+		})
+		return nil
+	})
+}
+
+// closeWatchConnection closes the watch connection of a spectator who joined the room as a player,
+// the repository has already taken them off the spectators
+func (s *RoomService) closeWatchConnection(room *model.Room, spectator model.Spectator) {
+	spectator.Conn.WriteJSON(map[string]interface{}{
+		"type":    model.MessageTypeSeatTaken,
+		"message": "You joined the room as a player, this connection no longer watches it.",
+	})
+	spectator.Conn.Close()
+
+	broadcast(room, map[string]interface{}{
+		"type":    model.MessageTypeSpectatorLeft,
+		"message": "A spectator has left.",
+		"data": map[string]interface{}{
+			"user":            spectator.User,
+			"spectator_count": len(room.Spectators),
+		},
+	})
+}
+
+// closeSpectators lets the spectators know the room is closing and disconnects them
+func closeSpectators(room *model.Room) {
+	for id, spectator := range room.Spectators {
+		spectator.Conn.WriteJSON(map[string]interface{}{
+			"type":    model.MessageTypeRoomClosed,
+			"message": "The room has been closed.",
+		})
+		spectator.Conn.Close()
+		delete(room.Spectators, id)
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+func TestSpectatorTakingASeatStopsWatching(t *testing.T) {
+	s, _ := newTestRoomService(t)
+	ctx := context.Background()
+	room, err := s.CreateRoom(ctx, testPlayer("alice"))
+	if err != nil {
+		t.Fatalf("CreateRoom: %v", err)
+	}
+
+	watchConn, watchClient := newTestConn(t)
+	carol := testPlayer("carol")
+	if _, err := s.WatchRoom(ctx, room.ID, model.Spectator{User: carol.User, Conn: watchConn}); err != nil {
+		t.Fatalf("WatchRoom: %v", err)
+	}
+	joined, err := s.JoinRoom(ctx, room.ID, carol)
+	if err != nil {
+		t.Fatalf("JoinRoom: %v", err)
+	}
+	if len(joined.Players) != 2 || joined.SpectatorCount != 0 {
+		t.Errorf("room has %d players and %d spectators, want 2 and 0", len(joined.Players), joined.SpectatorCount)
+	}
+
+	msg, ok := watchClient.next(time.Second)
+	if !ok || msg["type"] != string(model.MessageTypeSeatTaken) {
+		t.Fatalf("watch connection got %v, want %s", msg, model.MessageTypeSeatTaken)
+	}
+	if msg, ok := watchClient.next(time.Second); ok {
+		t.Errorf("watch connection still open, got %v", msg)
+	}
+	if !watchConn.IsClosed() {
+		t.Error("watch connection was not closed")
+	}
+}
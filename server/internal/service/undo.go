@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+var (
+	ErrorUndoNotSupported = errors.New("this game does not support undo")
+	ErrorNoUndosLeft      = errors.New("no undos left this round")
+	ErrorNoUndoRequest    = errors.New("your opponent has not asked to undo a move")
+)
+
+// HandleRequestUndo asks the player's opponent to let them take back their last move, a bot always agrees.
+// Undoing takes back the player's last move and the opponent's reply to it if there was one
+func (s *RoomService) HandleRequestUndo(ctx context.Context, roomID string, player model.Player) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requirePlayer(room, player); err != nil {
+			return err
+		}
+		if room.Status != model.RoomStatusGameStarted {
+			return ErrorGameNotInProgress
+		}
+		undoer, ok := room.Game.(model.Undoer)
+		if !ok {
+			return ErrorUndoNotSupported
+		}
+		if s.config.UndoLimit < 0 || room.UndosUsed[player.User.ID] >= s.config.UndoLimit {
+			return ErrorNoUndosLeft
+		}
+		if !hasMoveBy(undoer.History(), player.User.ID) {
+			return errors.New("you have no move to undo")
+		}
+		if room.UndoRequestedBy != "" {
+			return errors.New("an undo request is already waiting for an answer")
+		}
+
+		oppositePlayer, err := s.GetOppositePlayer(ctx, room.Players, player.User.ID)
+		if err != nil {
+			return err
+		}
+		if oppositePlayer.Bot != nil {
+			return s.undoMoves(room, player.User.ID)
+		}
+
+		room.UndoRequestedBy = player.User.ID
+		sendTo(room, oppositePlayer.User.ID, map[string]interface{}{
+			"type":    model.MessageTypeUndoRequested,
+			"message": "Your opponent asks to take back their last move.",
+			"data": map[string]interface{}{
+				"player_id": player.User.ID,
+			},
+		})
+		return nil
+	})
+}
+
+// HandleAcceptUndo takes back the opponent's last move when they asked for it
+func (s *RoomService) HandleAcceptUndo(ctx context.Context, roomID string, player model.Player) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requireUndoRequest(room, player); err != nil {
+			return err
+		}
+		return s.undoMoves(room, room.UndoRequestedBy)
+	})
+}
+
+// HandleDeclineUndo turns down the opponent's undo request and lets them know
+func (s *RoomService) HandleDeclineUndo(ctx context.Context, roomID string, player model.Player) error {
+	return s.withRoom(ctx, roomID, func(room *model.Room) error {
+		if err := requireUndoRequest(room, player); err != nil {
+			return err
+		}
+
+		requestedBy := room.UndoRequestedBy
+		room.UndoRequestedBy = ""
+		sendTo(room, requestedBy, map[string]interface{}{
+			"type":    model.MessageTypeUndoDeclined,
+			"message": "Your opponent has declined the undo.",
+		})
+		return nil
+	})
+}
+
+// undoMoves takes moves back until playerID's last move is undone, so it is their turn again,
+// and counts it against their undo limit
+func (s *RoomService) undoMoves(room *model.Room, playerID string) error {
+	undoer, ok := room.Game.(model.Undoer)
+	if !ok {
+		return ErrorUndoNotSupported
+	}
+	if !hasMoveBy(undoer.History(), playerID) {
+		return errors.New("no move to undo")
+	}
+
+	// the player to move is charged for their time so far, the clock then runs for whoever is to move after the undo
+	stopClock(room, false)
+	var undone []model.MoveRecord
+	for {
+		record, err := undoer.UndoMove()
+		if err != nil {
+			s.startClock(room)
+			return err
+		}
+		undone = append(undone, record)
+		if record.PlayerID == playerID {
+			break
+		}
+	}
+	room.RoundMoves = room.RoundMoves[:max(len(room.RoundMoves)-len(undone), 0)]
+	room.UndoRequestedBy = ""
+	room.UndosUsed[playerID]++
+	s.startClock(room)
+	s.publish(room, model.RoomEventTypeMoveUndone, playerID, undone)
+
+	broadcast(room, map[string]interface{}{
+		"type":    model.MessageTypeMoveUndone,
+		"message": "A move was taken back.",
+		"data":    room.GetRoomResponse(),
+	})
+	s.scheduleBotTurn(room)
+	return nil
+}
+
+// requireUndoRequest returns an error unless the round is running and the player's opponent asked for an undo
+func requireUndoRequest(room *model.Room, player model.Player) error {
+	if err := requirePlayer(room, player); err != nil {
+		return err
+	}
+	if room.Status != model.RoomStatusGameStarted {
+		return ErrorGameNotInProgress
+	}
+	if room.UndoRequestedBy == "" || room.UndoRequestedBy == player.User.ID {
+		return ErrorNoUndoRequest
+	}
+	return nil
+}
+
+// hasMoveBy reports whether the player made any of the moves
+func hasMoveBy(history []model.MoveRecord, playerID string) bool {
+	for _, record := range history {
+		if record.PlayerID == playerID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kaviraj-j/duoplay/internal/games/tictactoe"
+	"github.com/kaviraj-j/duoplay/internal/model"
+)
+
+// startUndoRound starts a round of tic tac toe where every player may undo limit times and plays
+// the moves in turn from the first seat, it returns the players in seat order
+func startUndoRound(t *testing.T, limit int, moves ...tictactoe.Move) (*RoomService, string, model.Player, model.Player) {
+	t.Helper()
+	s, _ := newTestRoomService(t)
+	s.config.UndoLimit = limit
+	roomID, _, _ := startTicTacToe(t, s)
+	firstID, secondID := seatedPlayers(s, roomID)
+	first, second := testPlayer(firstID), testPlayer(secondID)
+	for i, move := range moves {
+		player := first
+		if i%2 == 1 {
+			player = second
+		}
+		if err := s.HandleGameMove(context.Background(), roomID, player, move); err != nil {
+			t.Fatalf("move %d %+v: %v", i, move, err)
+		}
+	}
+	return s, roomID, first, second
+}
+
+// undoState returns the game's history, the match record's moves and the room's undo bookkeeping
+func undoState(s *RoomService, roomID string) (history []model.MoveRecord, roundMoves []model.MatchMove, requestedBy string, used map[string]int) {
+	s.withRoom(context.Background(), roomID, func(room *model.Room) error {
+		history = room.Game.(model.Undoer).History()
+		roundMoves = append(roundMoves, room.RoundMoves...)
+		requestedBy = room.UndoRequestedBy
+		used = make(map[string]int)
+		for id, n := range room.UndosUsed {
+			used[id] = n
+		}
+		return nil
+	})
+	return history, roundMoves, requestedBy, used
+}
+
+func TestUndoAccepted(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves []tictactoe.Move
+		// wantMoves is how many moves are left once the first seat's last move is taken back
+		wantMoves int
+	}{
+		{name: "opponent has replied", moves: []tictactoe.Move{{Row: 0, Col: 0}, {Row: 1, Col: 1}}, wantMoves: 0},
+		{name: "opponent has not replied", moves: []tictactoe.Move{{Row: 0, Col: 0}, {Row: 1, Col: 1}, {Row: 2, Col: 2}}, wantMoves: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, roomID, first, second := startUndoRound(t, 1, tt.moves...)
+			ctx := context.Background()
+
+			if err := s.HandleRequestUndo(ctx, roomID, first); err != nil {
+				t.Fatalf("HandleRequestUndo: %v", err)
+			}
+			if _, _, requestedBy, _ := undoState(s, roomID); requestedBy != first.User.ID {
+				t.Fatalf("undo requested by %q, want %q", requestedBy, first.User.ID)
+			}
+			if err := s.HandleAcceptUndo(ctx, roomID, second); err != nil {
+				t.Fatalf("HandleAcceptUndo: %v", err)
+			}
+
+			history, roundMoves, requestedBy, used := undoState(s, roomID)
+			if len(history) != tt.wantMoves || len(roundMoves) != tt.wantMoves {
+				t.Errorf("%d moves in the game and %d in the match record, want %d", len(history), len(roundMoves), tt.wantMoves)
+			}
+			for i := range roundMoves {
+				if i < len(history) && roundMoves[i].Move != history[i].Move {
+					t.Errorf("match record move %d = %+v, game has %+v", i, roundMoves[i].Move, history[i].Move)
+				}
+			}
+			if requestedBy != "" || used[first.User.ID] != 1 || used[second.User.ID] != 0 {
+				t.Errorf("after the undo requested by %q, undos used %v", requestedBy, used)
+			}
+			var current string
+			s.withRoom(ctx, roomID, func(room *model.Room) error {
+				current = room.Game.(model.TurnBasedGame).CurrentPlayerID()
+				return nil
+			})
+			if current != first.User.ID {
+				t.Errorf("%s is to move after the undo, want %s", current, first.User.ID)
+			}
+		})
+	}
+}
+
+func TestUndoDeclined(t *testing.T) {
+	s, roomID, first, second := startUndoRound(t, 1, tictactoe.Move{Row: 0, Col: 0}, tictactoe.Move{Row: 1, Col: 1})
+	ctx := context.Background()
+
+	if err := s.HandleRequestUndo(ctx, roomID, first); err != nil {
+		t.Fatalf("HandleRequestUndo: %v", err)
+	}
+	if err := s.HandleDeclineUndo(ctx, roomID, second); err != nil {
+		t.Fatalf("HandleDeclineUndo: %v", err)
+	}
+
+	history, roundMoves, requestedBy, used := undoState(s, roomID)
+	if len(history) != 2 || len(roundMoves) != 2 {
+		t.Errorf("%d moves in the game and %d in the match record after a decline, want 2", len(history), len(roundMoves))
+	}
+	if requestedBy != "" || used[first.User.ID] != 0 {
+		t.Errorf("after the decline requested by %q, undos used %v", requestedBy, used)
+	}
+	// the request is gone, so it cannot be accepted any more
+	if err := s.HandleAcceptUndo(ctx, roomID, second); !errors.Is(err, ErrorNoUndoRequest) {
+		t.Errorf("accepting a declined undo returned %v, want %v", err, ErrorNoUndoRequest)
+	}
+}
+
+func TestUndoRequesterCannotAnswer(t *testing.T) {
+	s, roomID, first, _ := startUndoRound(t, 1, tictactoe.Move{Row: 0, Col: 0})
+	ctx := context.Background()
+
+	if err := s.HandleRequestUndo(ctx, roomID, first); err != nil {
+		t.Fatalf("HandleRequestUndo: %v", err)
+	}
+	if err := s.HandleAcceptUndo(ctx, roomID, first); !errors.Is(err, ErrorNoUndoRequest) {
+		t.Errorf("requester accepting their own undo returned %v, want %v", err, ErrorNoUndoRequest)
+	}
+	if err := s.HandleDeclineUndo(ctx, roomID, first); !errors.Is(err, ErrorNoUndoRequest) {
+		t.Errorf("requester declining their own undo returned %v, want %v", err, ErrorNoUndoRequest)
+	}
+	if history, _, _, _ := undoState(s, roomID); len(history) != 1 {
+		t.Errorf("the game has %d moves, want 1", len(history))
+	}
+}
+
+func TestUndoLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		// wantUndos is how many undos the first seat gets before being refused
+		wantUndos int
+	}{
+		{name: "undos off", limit: -1, wantUndos: 0},
+		{name: "no undos", limit: 0, wantUndos: 0},
+		{name: "one undo", limit: 1, wantUndos: 1},
+		{name: "two undos", limit: 2, wantUndos: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, roomID, first, second := startUndoRound(t, tt.limit)
+			ctx := context.Background()
+
+			for i := 0; i < tt.wantUndos; i++ {
+				if err := s.HandleGameMove(ctx, roomID, first, tictactoe.Move{Row: 0, Col: 0}); err != nil {
+					t.Fatalf("HandleGameMove: %v", err)
+				}
+				if err := s.HandleRequestUndo(ctx, roomID, first); err != nil {
+					t.Fatalf("undo %d: %v", i+1, err)
+				}
+				if err := s.HandleAcceptUndo(ctx, roomID, second); err != nil {
+					t.Fatalf("accepting undo %d: %v", i+1, err)
+				}
+			}
+
+			if err := s.HandleGameMove(ctx, roomID, first, tictactoe.Move{Row: 0, Col: 0}); err != nil {
+				t.Fatalf("HandleGameMove: %v", err)
+			}
+			if err := s.HandleRequestUndo(ctx, roomID, first); !errors.Is(err, ErrorNoUndosLeft) {
+				t.Errorf("undo past the limit returned %v, want %v", err, ErrorNoUndosLeft)
+			}
+			if _, _, _, used := undoState(s, roomID); used[first.User.ID] != tt.wantUndos {
+				t.Errorf("undos used = %d, want %d", used[first.User.ID], tt.wantUndos)
+			}
+		})
+	}
+}
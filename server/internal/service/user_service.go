@@ -18,23 +18,40 @@ import (
 // 	ValidateToken(ctx context.Context, tokenString string) (*model.User, error)
 // }
 
+var (
+	ErrorNameNotAllowed = errors.New("name contains a word that is not allowed")
+)
+
 // userService implements UserService
 type UserService struct {
 	userRepository repository.UserRepository
 	jwtSecret      []byte
 	jwtExpires     time.Duration
+	// nameFilter rejects names with blocked words, nil allows every name
+	nameFilter *WordFilter
+	// adminIDs holds the users who can moderate reports
+	adminIDs map[string]bool
 }
 
-func CreateUserService(userRepository repository.UserRepository, jwtSecret []byte) (*UserService, error) {
+func CreateUserService(userRepository repository.UserRepository, jwtSecret []byte, nameFilter *WordFilter, adminIDs []string) (*UserService, error) {
+	admins := make(map[string]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
 	return &UserService{
 		userRepository: userRepository,
 		jwtSecret:      jwtSecret,
 		jwtExpires:     time.Hour * 24,
+		nameFilter:     nameFilter,
+		adminIDs:       admins,
 	}, nil
 }
 
 // RegisterUser registers a new user in user repo
 func (service *UserService) RegisterUser(ctx context.Context, name string) (*model.User, string, error) {
+	if service.nameFilter.Contains(name) {
+		return nil, "", ErrorNameNotAllowed
+	}
 	user := &model.User{
 		ID:   uuid.New().String(),
 		Name: name,
@@ -54,6 +71,11 @@ func (service *UserService) GetUserByID(ctx context.Context, id string) (*model.
 	return service.userRepository.FindByID(ctx, id)
 }
 
+// IsAdmin reports whether the user can moderate reports
+func (service *UserService) IsAdmin(userID string) bool {
+	return service.adminIDs[userID]
+}
+
 func (service *UserService) ValidateToken(ctx context.Context, tokenString string) (*model.User, error) {
 	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultBlockedWords is the word list used when the config does not give one
+var DefaultBlockedWords = []string{
+	"arsehole", "asshole", "bastard", "bitch", "bollocks", "bullshit", "cock", "cunt",
+	"dick", "fuck", "fucker", "fucking", "motherfucker", "nigger", "prick", "pussy",
+	"shit", "slut", "twat", "wanker", "whore",
+}
+
+// WordFilter finds the blocked words in text, words are matched whole and regardless of case.
+// A nil filter blocks nothing
+type WordFilter struct {
+	words map[string]bool
+}
+
+func NewWordFilter(words []string) *WordFilter {
+	filter := &WordFilter{words: make(map[string]bool, len(words))}
+	for _, word := range words {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			filter.words[word] = true
+		}
+	}
+	return filter
+}
+
+// Contains reports whether the text has a blocked word in it
+func (f *WordFilter) Contains(text string) bool {
+	found := false
+	f.eachBlocked(text, func(start, end int) {
+		found = true
+	})
+	return found
+}
+
+// Clean masks every blocked word in the text with asterisks
+func (f *WordFilter) Clean(text string) string {
+	var cleaned strings.Builder
+	last := 0
+	f.eachBlocked(text, func(start, end int) {
+		cleaned.WriteString(text[last:start])
+		cleaned.WriteString(strings.Repeat("*", len([]rune(text[start:end]))))
+		last = end
+	})
+	if last == 0 {
+		return text
+	}
+	cleaned.WriteString(text[last:])
+	return cleaned.String()
+}
+
+// eachBlocked calls fn with the byte range of every blocked word in the text, in order
+func (f *WordFilter) eachBlocked(text string, fn func(start, end int)) {
+	if f == nil || len(f.words) == 0 {
+		return
+	}
+	start := -1
+	for i, r := range text {
+		isWordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWordRune && start < 0 {
+			start = i
+		}
+		if !isWordRune && start >= 0 {
+			if f.words[strings.ToLower(text[start:i])] {
+				fn(start, i)
+			}
+			start = -1
+		}
+	}
+	if start >= 0 && f.words[strings.ToLower(text[start:])] {
+		fn(start, len(text))
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import "testing"
+
+func TestWordFilterContains(t *testing.T) {
+	filter := NewWordFilter([]string{"darn", " Heck ", "café"})
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"darn it", true},
+		{"DARN it", true},
+		{"oh heck!", true},
+		{"what the heck?!", true},
+		{"darned", false},
+		{"undarn", false},
+		// anything but a letter or digit separates words
+		{"darn_it", true},
+		{"un CAFÉ noir", true},
+		{"cafés", false},
+		{"", false},
+		{"nothing to see here", false},
+	}
+	for _, tt := range tests {
+		if got := filter.Contains(tt.text); got != tt.want {
+			t.Errorf("Contains(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestWordFilterClean(t *testing.T) {
+	filter := NewWordFilter([]string{"darn", "café", "ばか"})
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"darn it", "**** it"},
+		{"Darn, DARN and darned", "****, **** and darned"},
+		{"un café noir", "un **** noir"},
+		{"Café!", "****!"},
+		{"これは ばか です", "これは ** です"},
+		{"日本 darn 日本", "日本 **** 日本"},
+		{"clean text stays the same", "clean text stays the same"},
+	}
+	for _, tt := range tests {
+		if got := filter.Clean(tt.text); got != tt.want {
+			t.Errorf("Clean(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestNilWordFilterBlocksNothing(t *testing.T) {
+	for _, filter := range []*WordFilter{nil, NewWordFilter(nil)} {
+		if filter.Contains("darn fuck") {
+			t.Errorf("filter %v found a blocked word", filter)
+		}
+		if got := filter.Clean("darn fuck"); got != "darn fuck" {
+			t.Errorf("filter %v cleaned the text to %q", filter, got)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package ws wraps gorilla websocket connections so they can be written to from any goroutine
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	ErrConnClosed   = errors.New("connection closed")
+	ErrSlowConsumer = errors.New("connection dropped, client is not reading fast enough")
+)
+
+// Config controls the timeouts and buffering of a connection
+type Config struct {
+	// WriteWait is the time allowed to write a single message to the client
+	WriteWait time.Duration
+	// PongWait is how long the connection may stay silent before it is treated as dead
+	PongWait time.Duration
+	// PingInterval is how often the client is pinged, it must be shorter than PongWait
+	PingInterval time.Duration
+	// SendBuffer is how many messages can wait for the client before it is treated as a slow consumer
+	SendBuffer int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		WriteWait:    10 * time.Second,
+		PongWait:     60 * time.Second,
+		PingInterval: 54 * time.Second,
+		SendBuffer:   64,
+	}
+}
+
+// Conn is a websocket connection with a buffered outbound queue and a dedicated writer goroutine,
+// gorilla/websocket allows only one concurrent writer so every write goes through the queue
+type Conn struct {
+	conn   *websocket.Conn
+	config Config
+	send   chan []byte
+
+	// closing is closed when no more messages are accepted, done once the writer has exited
+	closing   chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewConn takes ownership of conn and starts its writer goroutine
+func NewConn(conn *websocket.Conn, config Config) *Conn {
+	if config.WriteWait <= 0 {
+		config.WriteWait = DefaultConfig().WriteWait
+	}
+	if config.SendBuffer <= 0 {
+		config.SendBuffer = DefaultConfig().SendBuffer
+	}
+	if config.PongWait <= 0 {
+		config.PongWait = DefaultConfig().PongWait
+	}
+	if config.PingInterval <= 0 || config.PingInterval >= config.PongWait {
+		config.PingInterval = config.PongWait * 9 / 10
+	}
+
+	c := &Conn{
+		conn:    conn,
+		config:  config,
+		send:    make(chan []byte, config.SendBuffer),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	// a client that stops answering pings hits the read deadline and its reader gets an error
+	conn.SetReadDeadline(time.Now().Add(config.PongWait))
+	conn.SetPongHandler(func(string) error {
+		return c.extendReadDeadline()
+	})
+
+	go c.writePump()
+	return c
+}
+
+// WriteJSON queues v for the client without blocking, a client whose queue is full is disconnected
+func (c *Conn) WriteJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-c.closing:
+		return ErrConnClosed
+	default:
+	}
+
+	select {
+	case c.send <- data:
+		return nil
+	default:
+		// the client is not keeping up, drop it instead of holding up the sender
+		c.abort()
+		return ErrSlowConsumer
+	}
+}
+
+// ReadMessage reads the next message from the client, only one goroutine may read at a time.
+// It fails once the client has been silent for longer than PongWait
+func (c *Conn) ReadMessage() (messageType int, p []byte, err error) {
+	messageType, p, err = c.conn.ReadMessage()
+	if err != nil {
+		return messageType, p, err
+	}
+	// any message shows the client is alive
+	c.extendReadDeadline()
+	return messageType, p, nil
+}
+
+func (c *Conn) extendReadDeadline() error {
+	return c.conn.SetReadDeadline(time.Now().Add(c.config.PongWait))
+}
+
+// Close flushes the messages already queued and closes the connection
+func (c *Conn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closing)
+	})
+	return nil
+}
+
+// Done is closed once the connection is closed and its writer has exited
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// IsClosed reports whether the connection no longer accepts messages
+func (c *Conn) IsClosed() bool {
+	select {
+	case <-c.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// abort closes the connection without flushing, the writer exits on its next write
+func (c *Conn) abort() {
+	c.Close()
+	c.conn.Close()
+}
+
+func (c *Conn) writePump() {
+	ticker := time.NewTicker(c.config.PingInterval)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+		close(c.done)
+	}()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.write(websocket.PingMessage, nil); err != nil {
+				c.Close()
+				return
+			}
+		case data := <-c.send:
+			if err := c.write(websocket.TextMessage, data); err != nil {
+				c.Close()
+				return
+			}
+		case <-c.closing:
+			c.flush()
+			c.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(c.config.WriteWait))
+			return
+		}
+	}
+}
+
+// flush writes whatever is still queued when the connection is closing
+func (c *Conn) flush() {
+	for {
+		select {
+		case data := <-c.send:
+			if err := c.write(websocket.TextMessage, data); err != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (c *Conn) write(messageType int, data []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(c.config.WriteWait))
+	return c.conn.WriteMessage(messageType, data)
+}
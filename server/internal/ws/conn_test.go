@@ -0,0 +1,135 @@
+package ws
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestConn returns a server side connection with the given config and the client connected to it,
+// both are closed when the test ends
+func newTestConn(t *testing.T, config Config) (*Conn, *websocket.Conn) {
+	t.Helper()
+	serverConns := make(chan *Conn, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		serverConns <- NewConn(conn, config)
+	}))
+	t.Cleanup(server.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dialing test server: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	conn := <-serverConns
+	t.Cleanup(func() { conn.Close() })
+	return conn, client
+}
+
+// readUntilError reads from the server side connection until it fails and sends the error on the returned channel
+func readUntilError(conn *Conn) <-chan error {
+	failed := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				failed <- err
+				return
+			}
+		}
+	}()
+	return failed
+}
+
+func TestSilentClientIsDisconnected(t *testing.T) {
+	config := Config{PongWait: 100 * time.Millisecond, PingInterval: 40 * time.Millisecond}
+
+	// gorilla answers pings while the client reads, so a client that reads stays connected
+	alive, aliveClient := newTestConn(t, config)
+	go func() {
+		for {
+			if _, _, err := aliveClient.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	// pongs are handled by the server's reader, as in the websocket handler it reads from the start
+	aliveFailed := readUntilError(alive)
+	// a client that never reads never answers a ping
+	silent, _ := newTestConn(t, config)
+
+	started := time.Now()
+	select {
+	case err := <-readUntilError(silent):
+		if waited := time.Since(started); waited < config.PongWait/2 {
+			t.Errorf("silent client dropped after %s, before the pong wait of %s", waited, config.PongWait)
+		}
+		if err == nil {
+			t.Error("reading from the silent client returned no error")
+		}
+	case <-time.After(20 * config.PongWait):
+		t.Fatalf("silent client still connected after %s", 20*config.PongWait)
+	}
+
+	select {
+	case err := <-aliveFailed:
+		t.Errorf("a client answering pings was dropped: %v", err)
+	case <-time.After(5 * config.PongWait):
+	}
+}
+
+func TestFullSendBufferClosesConnection(t *testing.T) {
+	// the client never reads, so once the socket buffers are full the writer is stuck on a write
+	// and the messages pile up in the send buffer
+	conn, client := newTestConn(t, Config{WriteWait: time.Minute, SendBuffer: 4})
+	message := map[string]string{"data": strings.Repeat("x", 1<<20)}
+
+	started := time.Now()
+	var err error
+	for sent := 0; err == nil; sent++ {
+		if sent == 1000 {
+			t.Fatalf("sent %d messages of 1MB to a client that does not read", sent)
+		}
+		err = conn.WriteJSON(message)
+	}
+	if !errors.Is(err, ErrSlowConsumer) {
+		t.Fatalf("WriteJSON returned %v, want %v", err, ErrSlowConsumer)
+	}
+	if waited := time.Since(started); waited > 10*time.Second {
+		t.Errorf("filling the send buffer took %s, the sender was held up", waited)
+	}
+
+	if !conn.IsClosed() {
+		t.Error("connection still open after its send buffer filled")
+	}
+	if err := conn.WriteJSON(message); !errors.Is(err, ErrConnClosed) {
+		t.Errorf("WriteJSON after the abort returned %v, want %v", err, ErrConnClosed)
+	}
+	select {
+	case <-conn.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the writer is still stuck on the slow client")
+	}
+
+	// the client finds the connection gone once it reads what made it through
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		if _, _, err := client.ReadMessage(); err != nil {
+			var netErr interface{ Timeout() bool }
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				t.Fatal("the client connection was not closed")
+			}
+			break
+		}
+	}
+}
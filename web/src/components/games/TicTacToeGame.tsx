@@ -37,6 +37,10 @@ const TicTacToeGame: React.FC<TicTacToeGameProps> = ({
   const getCellValue = (row: number, col: number): string => {
     const cellValue = gameState.Board[row][col];
     if (!cellValue) return "";
+    // The board holds seat marks
+    if (cellValue === "X" || cellValue === "O") {
+      return cellValue;
+    }
     // Map player ID to symbol
     if (cellValue === playerIds[0]) {
       return "X";
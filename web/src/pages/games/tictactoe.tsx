@@ -22,8 +22,10 @@ const TicTacToePage = () => {
         setGameStatus(room.game.status);
       }
 
-      // Get player IDs from room
-      const ids = Object.keys(room.players);
+      // Get player IDs in seat order, the first seat plays X
+      const ids = room.game.seats?.length
+        ? [...room.game.seats].sort((a, b) => a.index - b.index).map((seat) => seat.player_id)
+        : Object.keys(room.players);
       setPlayerIds(ids);
     }
   }, [room]);
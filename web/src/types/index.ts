@@ -26,10 +26,17 @@ export type GameType = "tictactoe";
 
 export type GameStatus = "not_started" | "in_progress" | "over";
 
+export interface Seat {
+  index: number;
+  mark: string;
+  player_id: string;
+}
+
 export interface GameState {
   type: GameType;
   status: GameStatus;
   state: TicTacToeState | Record<string, unknown>;
+  seats?: Seat[];
 }
 
 export type Room = {